@@ -0,0 +1,246 @@
+// Package breach looks passwords up in a local copy of the Have I Been Pwned "Pwned Passwords" list,
+// without network access.
+//
+// The list is a text file of "HASH:COUNT" lines sorted by hash, as written by the official
+// PwnedPasswordsDownloader, with the SHA-1 or NTLM hash of each password in hexadecimal.
+// Lookups binary search the file by byte offset, reading one line per step, so files of many
+// gigabytes need neither an index nor to be loaded into memory.
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // The Pwned Passwords list is keyed by SHA-1
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4" //nolint:staticcheck // NTLM hashes are MD4 by definition
+)
+
+// Hash types of Pwned Passwords files.
+const (
+	SHA1 = "sha1"
+	NTLM = "ntlm"
+)
+
+const (
+	// Lengths of the hexadecimal hashes.
+	sha1HexLength = 2 * sha1.Size
+	ntlmHexLength = 2 * md4.Size
+
+	// lineBufferSize is the read buffer per lookup step, a few times the length of a line.
+	lineBufferSize = 256
+)
+
+// Database is an opened Pwned Passwords file.
+type Database struct {
+	file     *os.File
+	name     string
+	size     int64
+	hashType string
+}
+
+// Result is the outcome of looking a password up.
+type Result struct {
+	// Database is the file name of the Pwned Passwords list.
+	Database string `json:"database"`
+	HashType string `json:"hashType"`
+	Breached bool   `json:"breached"`
+	// Count is the number of times the password was seen in breaches.
+	Count int64 `json:"count"`
+}
+
+// Open opens the Pwned Passwords file at path, telling SHA-1 from NTLM hashes by the first line.
+func Open(path string) (*Database, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening breach database: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+
+		return nil, fmt.Errorf("reading breach database: %w", err)
+	}
+
+	db := &Database{file: file, name: filepath.Base(path), size: info.Size()}
+
+	if err := db.detectHashType(); err != nil {
+		file.Close()
+
+		return nil, fmt.Errorf("%q is not a Pwned Passwords file: %w", path, err)
+	}
+
+	return db, nil
+}
+
+// detectHashType tells the hash type from the length of the first hash.
+func (db *Database) detectHashType() error {
+	first, err := db.lineAfter(0)
+	if err != nil {
+		return err
+	}
+
+	hash, _, err := parseLine(first)
+	if err != nil {
+		return err
+	}
+
+	switch len(hash) {
+	case sha1HexLength:
+		db.hashType = SHA1
+	case ntlmHexLength:
+		db.hashType = NTLM
+	default:
+		return fmt.Errorf("hash of %d hex digits is neither SHA-1 nor NTLM", len(hash))
+	}
+
+	return nil
+}
+
+// Close closes the file.
+func (db *Database) Close() error {
+	return db.file.Close()
+}
+
+// HashType returns the hash type of the file, SHA1 or NTLM.
+func (db *Database) HashType() string {
+	return db.hashType
+}
+
+// Lookup looks the password up, reporting whether and how often it was seen in breaches.
+func (db *Database) Lookup(password string) (Result, error) {
+	result := Result{Database: db.name, HashType: db.hashType}
+
+	target := db.hash(password)
+
+	// Find the smallest offset whose next line has a hash of at least target;
+	// the lines are sorted, so that is the line of target if the file has one.
+	low, high := int64(0), db.size
+
+	for low < high {
+		middle := low + (high-low)/2
+
+		hash, _, err := db.entryAfter(middle)
+		if err != nil {
+			return result, err
+		}
+
+		if hash == "" || hash >= target {
+			high = middle
+		} else {
+			low = middle + 1
+		}
+	}
+
+	hash, count, err := db.entryAfter(low)
+	if err != nil {
+		return result, err
+	}
+
+	if hash == target {
+		result.Breached = true
+		result.Count = count
+	}
+
+	return result, nil
+}
+
+// Contains reports whether the password was seen in breaches.
+func (db *Database) Contains(password string) (bool, error) {
+	result, err := db.Lookup(password)
+
+	return result.Breached, err
+}
+
+// hash returns the password's hash as it appears in the file, in uppercase hexadecimal.
+func (db *Database) hash(password string) string {
+	var sum []byte
+
+	if db.hashType == NTLM {
+		// NTLM is the MD4 of the password in UTF-16LE
+		encoded := utf16.Encode([]rune(password))
+		data := make([]byte, 0, 2*len(encoded))
+
+		for _, unit := range encoded {
+			data = binary.LittleEndian.AppendUint16(data, unit)
+		}
+
+		digest := md4.New()
+		digest.Write(data)
+		sum = digest.Sum(nil)
+	} else {
+		digest := sha1.Sum([]byte(password)) //nolint:gosec // The Pwned Passwords list is keyed by SHA-1
+		sum = digest[:]
+	}
+
+	return strings.ToUpper(hex.EncodeToString(sum))
+}
+
+// entryAfter returns the uppercase hash and the count of the first line starting at or after offset,
+// or an empty hash at the end of the file.
+func (db *Database) entryAfter(offset int64) (string, int64, error) {
+	line, err := db.lineAfter(offset)
+	if err != nil || line == "" {
+		return "", 0, err
+	}
+
+	hash, count, err := parseLine(line)
+	if err != nil {
+		return "", 0, fmt.Errorf("reading breach database at byte %d: %w", offset, err)
+	}
+
+	return strings.ToUpper(hash), count, nil
+}
+
+// lineAfter returns the first line starting at or after offset, without its line ending,
+// or "" at the end of the file.
+func (db *Database) lineAfter(offset int64) (string, error) {
+	start := max(offset-1, 0)
+	reader := bufio.NewReaderSize(io.NewSectionReader(db.file, start, db.size-start), lineBufferSize)
+
+	// Skip the rest of the line offset falls in, unless it starts right there
+	if offset > 0 {
+		if _, err := reader.ReadString('\n'); err != nil {
+			if errors.Is(err, io.EOF) {
+				return "", nil
+			}
+
+			return "", fmt.Errorf("reading breach database: %w", err)
+		}
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("reading breach database: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// parseLine splits a "HASH:COUNT" line.
+func parseLine(line string) (string, int64, error) {
+	hash, countText, found := strings.Cut(line, ":")
+	if !found {
+		return "", 0, fmt.Errorf("line %q is not HASH:COUNT", line)
+	}
+
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", 0, fmt.Errorf("line %q does not start with a hexadecimal hash", line)
+	}
+
+	count, err := strconv.ParseInt(strings.TrimSpace(countText), 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("line %q has an invalid count: %w", line, err)
+	}
+
+	return hash, count, nil
+}
@@ -0,0 +1,144 @@
+package breach
+
+import (
+	"crypto/sha1" //nolint:gosec // The Pwned Passwords list is keyed by SHA-1
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Known NTLM hashes of test passwords.
+const (
+	ntlmPassword = "8846F7EAEE8FB117AD06BDD830B7586C" // "password"
+	ntlmEmpty    = "31D6CFE0D16AE931B73C59D7E0C089C0" // ""
+)
+
+// sha1Hex returns the uppercase hexadecimal SHA-1 of password.
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec // The Pwned Passwords list is keyed by SHA-1
+
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeDatabase writes the "HASH:COUNT" lines of counts sorted by hash, each ended by newline,
+// and returns the path of the file.
+func writeDatabase(t *testing.T, counts map[string]int, newline string) string {
+	t.Helper()
+
+	lines := make([]string, 0, len(counts))
+
+	for hash, count := range counts {
+		lines = append(lines, fmt.Sprintf("%s:%d", hash, count))
+	}
+
+	slices.Sort(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, newline)+newline), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	passwords := map[string]int{"password": 9545824, "qwerty": 3946737, "letmein": 521723}
+	for i := range 50 {
+		passwords[fmt.Sprintf("filler-%d", i)] = i + 1
+	}
+
+	sha1Counts := make(map[string]int, len(passwords))
+	byHash := make(map[string]string, len(passwords))
+
+	for password, count := range passwords {
+		sha1Counts[sha1Hex(password)] = count
+		byHash[sha1Hex(password)] = password
+	}
+
+	hashes := slices.Sorted(maps.Keys(byHash))
+	first, last := byHash[hashes[0]], byHash[hashes[len(hashes)-1]]
+
+	ntlmCounts := map[string]int{
+		ntlmEmpty:                          100,
+		ntlmPassword:                       9545824,
+		"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF": 1,
+	}
+
+	firstCount, lastCount := int64(passwords[first]), int64(passwords[last])
+
+	tests := []struct {
+		name     string
+		hashType string
+		newline  string
+		password string
+		// want is the expected count, 0 if the password is missing.
+		want int64
+	}{
+		{name: "first entry", hashType: SHA1, newline: "\n", password: first, want: firstCount},
+		{name: "last entry", hashType: SHA1, newline: "\n", password: last, want: lastCount},
+		{name: "middle entry", hashType: SHA1, newline: "\n", password: "qwerty", want: 3946737},
+		{name: "missing hash", hashType: SHA1, newline: "\n", password: "not in the list"},
+		{name: "CRLF first entry", hashType: SHA1, newline: "\r\n", password: first, want: firstCount},
+		{name: "CRLF last entry", hashType: SHA1, newline: "\r\n", password: last, want: lastCount},
+		{name: "CRLF middle entry", hashType: SHA1, newline: "\r\n", password: "letmein", want: 521723},
+		{name: "CRLF missing hash", hashType: SHA1, newline: "\r\n", password: "not in the list"},
+		{name: "NTLM first entry", hashType: NTLM, newline: "\n", password: "", want: 100},
+		{name: "NTLM middle entry", hashType: NTLM, newline: "\r\n", password: "password", want: 9545824},
+		{name: "NTLM missing hash", hashType: NTLM, newline: "\n", password: "qwerty"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			counts := sha1Counts
+			if test.hashType == NTLM {
+				counts = ntlmCounts
+			}
+
+			db, err := Open(writeDatabase(t, counts, test.newline))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer db.Close()
+
+			if got := db.HashType(); got != test.hashType {
+				t.Errorf("HashType() = %q, want %q", got, test.hashType)
+			}
+
+			result, err := db.Lookup(test.password)
+			if err != nil {
+				t.Fatalf("Lookup(%q) error = %v", test.password, err)
+			}
+
+			if result.Breached != (test.want > 0) || result.Count != test.want {
+				t.Errorf("Lookup(%q) = %+v, want count %d", test.password, result, test.want)
+			}
+		})
+	}
+}
+
+func TestOpenRejectsOtherFiles(t *testing.T) {
+	t.Parallel()
+
+	contents := []string{"", "not a hash list\n", "ABCDEF:12\n", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8\n"}
+
+	for _, content := range contents {
+		path := filepath.Join(t.TempDir(), "list.txt")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if db, err := Open(path); err == nil {
+			db.Close()
+			t.Errorf("Open() of %q error = nil, want an error", content)
+		}
+	}
+}
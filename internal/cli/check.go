@@ -11,6 +11,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/idelchi/pwgen/internal/breach"
+	"github.com/idelchi/pwgen/internal/dictionary"
 	"github.com/idelchi/pwgen/internal/generate"
 	"github.com/idelchi/pwgen/internal/outfmt"
 )
@@ -27,6 +29,9 @@ const (
 type CheckOptions struct {
 	MinEntropy int
 	MinLength  int
+	Dict       string
+	BreachDB   string
+	BIP39      bool
 	JSON       bool
 }
 
@@ -40,13 +45,34 @@ func Check() *cobra.Command {
 		Long: `Read a passphrase from stdin and analyze its entropy and policy compliance.
 
 Calculates estimated entropy bits, checks length requirements, and provides
-a security assessment. Useful for validating existing passphrases.`,
+a security assessment. Useful for validating existing passphrases.
+
+The entropy is log2 of the guesses an attacker needs: common passwords, English words, names,
+keyboard walks, repeats, sequences and dates are recognized, and the cheapest combination of them,
+with brute force in between, is reported with what each part costs.
+
+Passphrases that pwgen could have generated, with words from --dict or any registered
+dictionary, are recognized: the pattern with their casing, separator, digits and symbols
+is rebuilt and its entropy reported next to the estimate.`,
 		Example: `  # Check a passphrase from stdin
   echo "correct-horse-battery-staple" | pwgen check
 
   # Check with minimum requirements
   echo "my-passphrase" | pwgen check --min-entropy 60 --min-length 20
 
+  # Recognize a generated passphrase and report its pattern's entropy
+  echo "Gumming-Breeder-Certainly-Ozone-7" | pwgen check
+
+  # Score words from a dictionary by its entropy per word
+  echo "correct-horse-battery-staple" | pwgen check --dict eff
+
+  # Look the passphrase up in a downloaded Pwned Passwords list, offline
+  echo "P@ssw0rd" | pwgen check --breach-db ./pwnedpasswords.txt
+
+  # Validate a BIP39 mnemonic's words and checksum
+  echo "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about" |
+    pwgen check --bip39
+
   # Get results in JSON format
   echo "test123" | pwgen check --json`,
 		RunE: func(_ *cobra.Command, _ []string) error {
@@ -56,6 +82,11 @@ a security assessment. Useful for validating existing passphrases.`,
 
 	cmd.Flags().IntVar(&opts.MinEntropy, "min-entropy", opts.MinEntropy, "Minimum entropy requirement")
 	cmd.Flags().IntVar(&opts.MinLength, "min-length", opts.MinLength, "Minimum length requirement")
+	cmd.Flags().StringVar(&opts.Dict, "dict", opts.Dict,
+		"Dictionary whose words to recognize: eff|eff-short|eff-short2|bip39-en|cs|de|es|fr|it|name|path")
+	cmd.Flags().StringVar(&opts.BreachDB, "breach-db", opts.BreachDB,
+		"Pwned Passwords file (HASH:COUNT lines sorted by SHA-1 or NTLM hash) to look the passphrase up in")
+	cmd.Flags().BoolVar(&opts.BIP39, "bip39", opts.BIP39, "Validate the input as a BIP39 mnemonic (English wordlist)")
 	cmd.Flags().BoolVar(&opts.JSON, "json", opts.JSON, "Output in JSON format")
 
 	cmd.Flags().SortFlags = false
@@ -65,41 +96,48 @@ a security assessment. Useful for validating existing passphrases.`,
 
 // runCheck executes the passphrase analysis.
 func runCheck(opts *CheckOptions) error {
-	// Check if stdin has data with a short timeout
-	done := make(chan bool, 1)
+	passphrase, err := readStdinLine("passphrase")
+	if err != nil {
+		return err
+	}
 
-	var (
-		passphrase string
-		scanErr    error
-	)
+	// Analyze the passphrase
+	calculator := generate.NewEntropyCalculator()
 
-	go func() {
-		scanner := bufio.NewScanner(os.Stdin)
-		if scanner.Scan() {
-			passphrase = strings.TrimSpace(scanner.Text())
+	if opts.Dict != "" {
+		dict, err := dictionary.GetDictionary(opts.Dict)
+		if err != nil {
+			return fmt.Errorf("loading dictionary: %w", err)
 		}
 
-		scanErr = scanner.Err()
+		calculator.SetDictionary(dict)
+	} else {
+		calculator.SetPatternDictionaries(builtinDictionaries())
+	}
 
-		done <- true
-	}()
+	analysis := calculator.CalculateEntropy(passphrase)
 
-	select {
-	case <-done:
-		if scanErr != nil {
-			return fmt.Errorf("reading from stdin: %w", scanErr)
+	if opts.BreachDB != "" {
+		result, err := lookupBreach(opts.BreachDB, passphrase)
+		if err != nil {
+			return err
 		}
 
-		if passphrase == "" {
-			return errors.New("no passphrase provided on stdin")
+		analysis.Breach = &result
+
+		if result.Breached {
+			fmt.Fprintf(os.Stderr, "Policy violation: passphrase seen %d times in data breaches\n", result.Count)
 		}
-	case <-time.After(stdinReadTimeout):
-		return errors.New("no input provided on stdin")
 	}
 
-	// Analyze the passphrase
-	calculator := generate.NewEntropyCalculator()
-	analysis := calculator.CalculateEntropy(passphrase)
+	if opts.BIP39 {
+		mnemonic := generate.CheckMnemonic(passphrase)
+		analysis.Mnemonic = &mnemonic
+
+		if !mnemonic.Valid {
+			fmt.Fprintf(os.Stderr, "Policy violation: not a valid BIP39 mnemonic: %s\n", mnemonic.Error)
+		}
+	}
 
 	// Check policy if requirements specified
 	if opts.MinEntropy > 0 && analysis.Entropy < float64(opts.MinEntropy) {
@@ -128,3 +166,89 @@ func runCheck(opts *CheckOptions) error {
 
 	return formatter.FormatAnalysis(analysis)
 }
+
+// lookupBreach looks the passphrase up in the Pwned Passwords file at path.
+func lookupBreach(path, passphrase string) (breach.Result, error) {
+	db, err := breach.Open(path)
+	if err != nil {
+		return breach.Result{}, err
+	}
+	defer db.Close()
+
+	result, err := db.Lookup(passphrase)
+	if err != nil {
+		return result, fmt.Errorf("looking up passphrase: %w", err)
+	}
+
+	return result, nil
+}
+
+// builtinDictionaries loads every registered dictionary, to recognize the passphrases made from any of them.
+func builtinDictionaries() []dictionary.Dictionary {
+	builtins := dictionary.ListBuiltin()
+	dicts := make([]dictionary.Dictionary, 0, len(builtins))
+
+	for _, info := range builtins {
+		dicts = append(dicts, info.Factory())
+	}
+
+	return dicts
+}
+
+// readStdinLine reads the first line of stdin, trimmed, naming it what in errors.
+func readStdinLine(what string) (string, error) {
+	lines, err := readStdin(what, false)
+	if err != nil {
+		return "", err
+	}
+
+	return lines[0], nil
+}
+
+// readStdinLines reads the non-empty lines of stdin, trimmed, naming them what in errors.
+func readStdinLines(what string) ([]string, error) {
+	return readStdin(what, true)
+}
+
+// readStdin reads the first line of stdin, or all of its non-empty lines, trimmed.
+func readStdin(what string, all bool) ([]string, error) {
+	// Check if stdin has data with a short timeout
+	done := make(chan bool, 1)
+
+	var (
+		lines   []string
+		scanErr error
+	)
+
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				lines = append(lines, line)
+			}
+
+			if !all {
+				break
+			}
+		}
+
+		scanErr = scanner.Err()
+
+		done <- true
+	}()
+
+	select {
+	case <-done:
+		if scanErr != nil {
+			return nil, fmt.Errorf("reading from stdin: %w", scanErr)
+		}
+
+		if len(lines) == 0 {
+			return nil, fmt.Errorf("no %s provided on stdin", what)
+		}
+	case <-time.After(stdinReadTimeout):
+		return nil, errors.New("no input provided on stdin")
+	}
+
+	return lines, nil
+}
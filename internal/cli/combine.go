@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/idelchi/pwgen/internal/clipboard"
+	"github.com/idelchi/pwgen/internal/shamir"
+)
+
+// CombineOptions represents the configuration for the combine command.
+type CombineOptions struct {
+	Dict       string
+	DictFormat string
+	Copy       bool
+}
+
+// Combine returns the combine command.
+func Combine() *cobra.Command {
+	opts := &CombineOptions{
+		Dict:       "eff",
+		DictFormat: "auto",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "combine [shares...]",
+		Short: "Reconstruct a passphrase from the shares made by split",
+		Long: `Reconstruct a passphrase from a threshold of the shares made by "pwgen split".
+
+Shares are read from the arguments, one share per argument, or without arguments
+from stdin, one share per line. Lines may keep the "Share 1/5:" label printed by split.
+The order of the shares does not matter, and words are matched regardless of case.
+
+The dictionary must be the one the shares were written with.`,
+		Example: `  # Combine shares typed into a file, one per line
+  pwgen combine < shares.txt
+
+  # Combine shares given as arguments
+  pwgen combine "catapult booth landslide ..." "uncle jolly huskiness ..." "platter density ..."
+
+  # Combine shares written with the short word list and copy the passphrase
+  pwgen combine --dict eff-short2 --copy < shares.txt`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runCombine(opts, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Dict, "dict", opts.Dict,
+		"Dictionary the shares are written with: eff|eff-short|eff-short2|bip39-en|cs|de|es|fr|it|name|path")
+	cmd.Flags().StringVar(&opts.DictFormat, "dict-format", opts.DictFormat,
+		"Format of a dictionary file: auto|plain|diceware|tsv|csv")
+	cmd.Flags().BoolVar(&opts.Copy, "copy", opts.Copy, "Copy result to clipboard")
+
+	cmd.Flags().SortFlags = false
+
+	return cmd
+}
+
+// runCombine reconstructs the passphrase from the entered shares.
+func runCombine(opts *CombineOptions, lines []string) error {
+	if len(lines) == 0 {
+		var err error
+
+		if lines, err = readStdinLines("shares"); err != nil {
+			return err
+		}
+	}
+
+	dict, err := loadDictionary(DictOptions{Dict: opts.Dict, Format: opts.DictFormat})
+	if err != nil {
+		return err
+	}
+
+	// The "Share 1/5:" label that split prints before each share
+	label := regexp.MustCompile(`(?i)^(share\s+)?\d+(/\d+)?:`)
+
+	shares := make([]shamir.Share, 0, len(lines))
+
+	defer func() {
+		wipeShares(shares)
+	}()
+
+	for i, line := range lines {
+		words := strings.Fields(label.ReplaceAllString(strings.TrimSpace(line), ""))
+
+		share, err := shamir.ParseShare(words, dict)
+		if err != nil {
+			return fmt.Errorf("share on line %d: %w", i+1, err)
+		}
+
+		shares = append(shares, share)
+	}
+
+	secret, err := shamir.Combine(shares)
+	if err != nil {
+		return fmt.Errorf("combining shares: %w", err)
+	}
+	defer secret.Wipe()
+
+	passphrase := secret.String()
+
+	if opts.Copy {
+		if err := clipboard.Copy(passphrase); err != nil {
+			// Don't fail the command, just warn
+			fmt.Fprintf(os.Stderr, "Warning: failed to copy to clipboard: %v\n", err)
+		}
+	}
+
+	_, err = fmt.Fprintln(os.Stdout, passphrase)
+
+	return err
+}
+
+// wipeShares wipes the values of the shares.
+func wipeShares(shares []shamir.Share) {
+	for _, share := range shares {
+		share.Wipe()
+	}
+}
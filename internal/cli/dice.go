@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/idelchi/pwgen/internal/clipboard"
+	"github.com/idelchi/pwgen/internal/generate"
+	"github.com/idelchi/pwgen/internal/outfmt"
+)
+
+// DiceOptions represents the configuration for the dice command.
+type DiceOptions struct {
+	Sep        string
+	Caps       string
+	Dict       string
+	DictFormat string
+	JSON       bool
+	Copy       bool
+}
+
+// Dice returns the dice command.
+func Dice() *cobra.Command {
+	opts := &DiceOptions{
+		Sep:        "-",
+		Caps:       "lower",
+		Dict:       "eff",
+		DictFormat: "auto",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "dice [rolls...]",
+		Short: "Build a passphrase from physical dice rolls",
+		Long: `Build a passphrase from words picked with real dice, for credentials generated offline.
+
+Roll five dice per word (four for eff-short and eff-short2) and enter the faces in order, e.g. 16655.
+Each roll is validated and looked up in the numbered diceware list. Rolls are read from
+the arguments or, without arguments, from the first line of stdin.
+
+The entropy is that of words picked at random, as with gen. Random casing styles
+(mixed, random-title) add computer randomness; the default lower casing uses the dice alone.`,
+		Example: `  # Five words from the EFF list
+  pwgen dice 16655 15653 62534 22124 43156
+
+  # Title case with spaces
+  pwgen dice --caps title --sep " " 16655 15653 62534 22124 43156
+
+  # Four dice per word with the short list
+  echo "1111 6666 3456 2345 5432 1234" | pwgen dice --dict eff-short2`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runDice(opts, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Sep, "sep", opts.Sep, "Separator between words")
+	cmd.Flags().StringVar(&opts.Caps, "caps", opts.Caps,
+		"Casing style: lower|upper|title|alternating|first-upper|inverted-title|mixed|random-title")
+	cmd.Flags().StringVar(&opts.Dict, "dict", opts.Dict,
+		"Diceware dictionary the rolls index: eff|eff-short|eff-short2|name|path")
+	cmd.Flags().StringVar(&opts.DictFormat, "dict-format", opts.DictFormat,
+		"Format of a dictionary file: auto|plain|diceware|tsv|csv")
+	cmd.Flags().BoolVar(&opts.JSON, "json", opts.JSON, "Output in JSON format")
+	cmd.Flags().BoolVar(&opts.Copy, "copy", opts.Copy, "Copy result to clipboard")
+
+	cmd.Flags().SortFlags = false
+
+	return cmd
+}
+
+// runDice builds the passphrase from the entered rolls.
+func runDice(opts *DiceOptions, rolls []string) error {
+	if len(rolls) == 0 {
+		line, err := readStdinLine("dice rolls")
+		if err != nil {
+			return err
+		}
+
+		rolls = strings.Fields(line)
+	}
+
+	dict, err := loadDictionary(DictOptions{Dict: opts.Dict, Format: opts.DictFormat})
+	if err != nil {
+		return err
+	}
+
+	result, err := generate.NewGenerator(dict, opts.Sep).GenerateFromRolls(rolls, opts.Caps, opts.Sep)
+	if err != nil {
+		return fmt.Errorf("building passphrase: %w", err)
+	}
+
+	if opts.Copy {
+		if err := clipboard.Copy(result.Passphrase); err != nil {
+			// Don't fail the command, just warn
+			fmt.Fprintf(os.Stderr, "Warning: failed to copy to clipboard: %v\n", err)
+		}
+	}
+
+	format := formatText
+	if opts.JSON {
+		format = formatJSON
+	}
+
+	formatter := outfmt.NewFormatter(format, os.Stdout, outfmt.Options{
+		Colors:  !opts.JSON,
+		Verbose: true,
+	})
+
+	return formatter.FormatResults([]generate.Result{result})
+}
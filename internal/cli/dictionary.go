@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+
+	"golang.org/x/text/language"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+)
+
+// DictOptions represents the dictionary selection shared by several commands.
+type DictOptions struct {
+	Dict   string
+	Format string
+	Lang   string
+	ASCII  bool
+}
+
+// WordFilterOptions represents the word filters of the gen command.
+type WordFilterOptions struct {
+	MinWordLen   int
+	MaxWordLen   int
+	ExcludeWords string
+	IncludeRegex string
+	ExcludeRegex string
+}
+
+// loadDictionary resolves the dictionary described by opts.
+// An explicit language overrides the dictionary's own and ASCII transliterates its words.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func loadDictionary(opts DictOptions) (dictionary.Dictionary, error) {
+	format, err := dictionary.ParseFormat(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	dict, err := dictionary.GetDictionaryFormat(opts.Dict, format)
+	if err != nil {
+		return nil, fmt.Errorf("loading dictionary: %w", err)
+	}
+
+	if opts.Lang != "" {
+		lang, err := language.Parse(opts.Lang)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dictionary language %q: %w", opts.Lang, err)
+		}
+
+		dict = dictionary.WithLanguage(dict, lang)
+	}
+
+	if opts.ASCII {
+		dict = dictionary.ASCII(dict)
+	}
+
+	return dict, nil
+}
+
+// filter builds the word filter described by opts.
+func (opts WordFilterOptions) filter() (dictionary.FilterOptions, error) {
+	filter := dictionary.FilterOptions{
+		MinLength: opts.MinWordLen,
+		MaxLength: opts.MaxWordLen,
+	}
+
+	var err error
+
+	if opts.IncludeRegex != "" {
+		if filter.Include, err = regexp.Compile(opts.IncludeRegex); err != nil {
+			return filter, fmt.Errorf("invalid include regex: %w", err)
+		}
+	}
+
+	if opts.ExcludeRegex != "" {
+		if filter.Exclude, err = regexp.Compile(opts.ExcludeRegex); err != nil {
+			return filter, fmt.Errorf("invalid exclude regex: %w", err)
+		}
+	}
+
+	if opts.ExcludeWords != "" {
+		if filter.Blocklist, err = dictionary.LoadBlocklist(opts.ExcludeWords); err != nil {
+			return filter, err
+		}
+	}
+
+	return filter, nil
+}
@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"fmt"
 	"os"
+	"sort"
 
 	"github.com/spf13/cobra"
 
@@ -23,13 +25,18 @@ func Dicts() *cobra.Command {
 		Short: "List available dictionaries",
 		Long: `List all available dictionaries with their metadata.
 
-Shows built-in dictionaries (eff, small) and any external dictionaries
-that can be loaded from file paths. Includes word count and entropy information.`,
+Shows the built-in dictionaries and the named dictionaries from the
+user dictionary directory ($XDG_CONFIG_HOME/pwgen/dicts on Linux). Every file
+there is available by its base name, e.g. "nouns.txt" as --dict nouns.
+Includes word count and entropy information.`,
 		Example: `  # List all dictionaries
   pwgen dicts
 
   # Get dictionary info in JSON format
-  pwgen dicts --json`,
+  pwgen dicts --json
+
+  # Validate a word list file
+  pwgen dicts validate ./wordlist.txt`,
 		RunE: func(_ *cobra.Command, _ []string) error {
 			return runDicts(opts)
 		},
@@ -39,6 +46,50 @@ that can be loaded from file paths. Includes word count and entropy information.
 
 	cmd.Flags().SortFlags = false
 
+	cmd.AddCommand(DictsValidate())
+
+	return cmd
+}
+
+// DictsValidateOptions represents the configuration for the dicts validate command.
+type DictsValidateOptions struct {
+	Format string
+	JSON   bool
+}
+
+// DictsValidate returns the dicts validate command.
+func DictsValidate() *cobra.Command {
+	opts := &DictsValidateOptions{
+		Format: "auto",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "validate <path>",
+		Short: "Validate a dictionary",
+		Long: `Check a word list for problems that reduce passphrase entropy.
+
+Reports duplicate words, words that collide once casing is applied, empty
+words (blank lines in a file) and words containing whitespace, and whether
+the list is prefix-free.
+Entropy per word is computed on the number of distinct words.
+Accepts a file path or the name of a built-in dictionary.`,
+		Example: `  # Validate a word list file
+  pwgen dicts validate ./wordlist.txt
+
+  # Validate a numbered diceware list and get JSON output
+  pwgen dicts validate ./diceware.txt --dict-format diceware --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runDictsValidate(args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Format, "dict-format", opts.Format,
+		"Format of the dictionary file: auto|plain|diceware|tsv|csv")
+	cmd.Flags().BoolVar(&opts.JSON, "json", opts.JSON, "Output in JSON format")
+
+	cmd.Flags().SortFlags = false
+
 	return cmd
 }
 
@@ -47,7 +98,13 @@ func runDicts(opts *DictsOptions) error {
 	// Get built-in dictionaries
 	builtins := dictionary.ListBuiltin()
 
-	dictInfos := make([]outfmt.DictionaryInfo, 0, len(builtins))
+	// Get named dictionaries from the user config directory
+	users, err := dictionary.ListUser()
+	if err != nil {
+		return err
+	}
+
+	dictInfos := make([]outfmt.DictionaryInfo, 0, len(builtins)+len(users))
 
 	for _, builtin := range builtins {
 		info := outfmt.DictionaryInfoFromBuiltin(builtin)
@@ -55,13 +112,30 @@ func runDicts(opts *DictsOptions) error {
 		dictInfos = append(dictInfos, info)
 	}
 
+	for _, user := range users {
+		dict, err := user.Load(dictionary.FormatAuto)
+		if err != nil {
+			// Don't fail the listing, just warn
+			fmt.Fprintf(os.Stderr, "Warning: skipping dictionary %q: %v\n", user.Name, err)
+
+			continue
+		}
+
+		dictInfos = append(dictInfos, outfmt.DictionaryInfoFromFile(user.Path, dict))
+	}
+
+	// Keep the listing stable across runs
+	sort.SliceStable(dictInfos, func(i, j int) bool {
+		return dictInfos[i].Name < dictInfos[j].Name
+	})
+
 	// Format output
 	var format string
 
 	if opts.JSON {
-		format = "json"
+		format = formatJSON
 	} else {
-		format = "text"
+		format = formatText
 	}
 
 	formatter := outfmt.NewFormatter(format, os.Stdout, outfmt.Options{
@@ -71,3 +145,32 @@ func runDicts(opts *DictsOptions) error {
 
 	return formatter.FormatDictionaries(dictInfos)
 }
+
+// runDictsValidate executes the dictionary validation.
+func runDictsValidate(source string, opts *DictsValidateOptions) error {
+	dictFormat, err := dictionary.ParseFormat(opts.Format)
+	if err != nil {
+		return err
+	}
+
+	report, err := dictionary.ValidateSource(source, dictFormat)
+	if err != nil {
+		return err
+	}
+
+	// Format output
+	var format string
+
+	if opts.JSON {
+		format = formatJSON
+	} else {
+		format = formatText
+	}
+
+	formatter := outfmt.NewFormatter(format, os.Stdout, outfmt.Options{
+		Colors:  !opts.JSON,
+		Verbose: true,
+	})
+
+	return formatter.FormatValidation(report)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/idelchi/pwgen/internal/breach"
 	"github.com/idelchi/pwgen/internal/clipboard"
 	"github.com/idelchi/pwgen/internal/dictionary"
 	"github.com/idelchi/pwgen/internal/generate"
@@ -14,21 +15,38 @@ import (
 
 // GenOptions represents the configuration for the generate command.
 type GenOptions struct {
-	Words      int
-	Sep        string
-	Caps       string
-	Digits     int
-	Symbols    int
-	Pattern    string
-	Dict       string
-	Kebab      bool
-	Snake      bool
-	Camel      bool
-	Count      int
-	JSON       bool
-	Copy       bool
-	MinEntropy int
-	MinLength  int
+	Words        int
+	Sep          string
+	Caps         string
+	Digits       int
+	Symbols      int
+	Placement    string
+	Pattern      string
+	Chars        int
+	Hex          int
+	Mnemonic     int
+	Dict         string
+	DictFormat   string
+	DictLang     string
+	ASCII        bool
+	MinWordLen   int
+	MaxWordLen   int
+	ExcludeWords string
+	IncludeRegex string
+	ExcludeRegex string
+	Kebab        bool
+	Snake        bool
+	Camel        bool
+	Count        int
+	JSON         bool
+	Copy         bool
+	Explain      bool
+	MinEntropy   int
+	MinLength    int
+	MaxLength    int
+	Policy       string
+	ShowRolls    bool
+	BreachDB     string
 }
 
 const (
@@ -39,13 +57,15 @@ const (
 // Gen returns the generate command.
 func Gen() *cobra.Command {
 	opts := &GenOptions{
-		Words:   defaultWordCount,
-		Sep:     "-",
-		Caps:    "mixed",
-		Digits:  0,
-		Symbols: 0,
-		Dict:    "eff",
-		Count:   1,
+		Words:      defaultWordCount,
+		Sep:        "-",
+		Caps:       "mixed",
+		Digits:     0,
+		Symbols:    0,
+		Placement:  "end",
+		Dict:       "eff",
+		DictFormat: "auto",
+		Count:      1,
 	}
 
 	cmd := &cobra.Command{
@@ -54,21 +74,68 @@ func Gen() *cobra.Command {
 		Long: `Generate passphrases using configurable options.
 
 Supports word-based generation with customizable separators, casing,
-digits, symbols, and patterns. Output can be plain text or JSON format.`,
+digits, symbols, and patterns, as well as random character strings. Output can be plain text or JSON format.`,
 		Example: `  # Generate default passphrase (4 words, mixed case, hyphen-separated)
   pwgen gen
 
   # Generate with specific options
   pwgen gen --words 5 --sep "." --caps title --digits 2 --symbols 1
 
+  # Scatter the digits and symbols between the words, or inside them
+  pwgen gen --digits 2 --symbols 1 --placement random
+  pwgen gen --digits 2 --symbols 1 --placement inside
+
   # Generate using custom pattern
-  pwgen gen --pattern "W:title SEP W:lower SEP DD{2} SEP S"
+  pwgen gen --pattern "W:title SEP W:lower SEP D{2} SEP S"
+
+  # Literal prefix, repeated group and a choice between digits and symbols
+  pwgen gen --pattern '"corp-" (W SEP(".")){3} D{4}|S{2}'
+
+  # Random API key and hex token
+  pwgen gen --chars 32
+  pwgen gen --hex 64
+  pwgen gen --pattern '"sk_" [a-z0-9]{24}'
+
+  # BIP39 mnemonic: 12 words (128 bits) or 24 words (256 bits)
+  pwgen gen --mnemonic 128
+  pwgen gen --mnemonic 256
+
+  # Mix dictionaries for "adjective-noun" passphrases
+  pwgen gen --pattern "W@adjectives:title SEP W@nouns SEP DD"
+
+  # Use only short words and skip a blocklist
+  pwgen gen --min-word-len 3 --max-word-len 6 --exclude-words ./blocklist.txt
+
+  # Generate a Spanish passphrase without accents
+  pwgen gen --dict es --ascii
+
+  # Generate a German passphrase with capitalized words
+  pwgen gen --dict de --caps title
+
+  # Generate from a numbered diceware list
+  pwgen gen --dict ./wordlist.txt --dict-format diceware
+
+  # Fit a 24-character limit
+  pwgen gen --words 4 --max-length 24
+
+  # Follow a site's password rules, retrying until every result complies
+  pwgen gen --words 3 --digits 1 --policy ./site-policy.json
+
+  # Never output a passphrase found in a downloaded Pwned Passwords list
+  pwgen gen --avoid-breached ./pwnedpasswords.txt
+
+  # Print the dice rolls of the words, to check them against the printed EFF list
+  pwgen gen --show-rolls
+
+  # Show the equivalent pattern and where the entropy comes from
+  pwgen gen --words 3 --digits 2 --explain
 
   # Generate multiple passphrases in JSON format
   pwgen gen --count 3 --json
 
   # Generate and copy to clipboard
   pwgen gen --copy`,
+		Args: cobra.NoArgs,
 		RunE: func(_ *cobra.Command, _ []string) error {
 			return runGenerate(opts)
 		},
@@ -76,19 +143,59 @@ digits, symbols, and patterns. Output can be plain text or JSON format.`,
 
 	cmd.Flags().IntVar(&opts.Words, "words", opts.Words, "Number of words to generate")
 	cmd.Flags().StringVar(&opts.Sep, "sep", opts.Sep, "Separator between tokens")
-	cmd.Flags().StringVar(&opts.Caps, "caps", opts.Caps, "Casing style: mixed|lower|upper|title")
+	cmd.Flags().StringVar(&opts.Caps, "caps", opts.Caps,
+		"Casing style: mixed|lower|upper|title|alternating|random-title|first-upper|inverted-title")
 	cmd.Flags().IntVar(&opts.Digits, "digits", opts.Digits, "Number of digit tokens")
 	cmd.Flags().IntVar(&opts.Symbols, "symbols", opts.Symbols, "Number of symbol tokens")
+	cmd.Flags().StringVar(&opts.Placement, "placement", opts.Placement,
+		"Where digits and symbols go: end|random (between words)|inside (within words)")
 	cmd.Flags().StringVar(&opts.Pattern, "pattern", opts.Pattern, "Custom pattern (overrides other options)")
-	cmd.Flags().StringVar(&opts.Dict, "dict", opts.Dict, "Dictionary to use: eff|small|path")
+	cmd.Flags().IntVar(&opts.Chars, "chars", opts.Chars,
+		"Generate a random alphanumeric string of this length instead of words")
+	cmd.Flags().IntVar(&opts.Hex, "hex", opts.Hex, "Generate a random hex string of this length instead of words")
+	cmd.Flags().IntVar(&opts.Mnemonic, "mnemonic", opts.Mnemonic,
+		"Generate a BIP39 mnemonic with this many bits of entropy instead of words: 128|160|192|224|256")
+	cmd.Flags().StringVar(&opts.Dict, "dict", opts.Dict,
+		"Dictionary to use: eff|eff-short|eff-short2|bip39-en|cs|de|es|fr|it|name|path")
+	cmd.Flags().StringVar(&opts.DictFormat, "dict-format", opts.DictFormat,
+		"Format of a dictionary file: auto|plain|diceware|tsv|csv")
+	cmd.Flags().StringVar(&opts.DictLang, "dict-lang", opts.DictLang,
+		"Language of the dictionary for casing rules, e.g. de (default: the dictionary's own)")
+	cmd.Flags().BoolVar(&opts.ASCII, "ascii", opts.ASCII, "Transliterate words to ASCII (e.g. ü -> u, ß -> ss)")
+	cmd.Flags().IntVar(&opts.MinWordLen, "min-word-len", opts.MinWordLen, "Only use words with at least this many letters")
+	cmd.Flags().IntVar(&opts.MaxWordLen, "max-word-len", opts.MaxWordLen, "Only use words with at most this many letters")
+	cmd.Flags().StringVar(&opts.ExcludeWords, "exclude-words", opts.ExcludeWords,
+		"File of words to never use, one per line")
+	cmd.Flags().StringVar(&opts.IncludeRegex, "include-regex", opts.IncludeRegex, "Only use words matching this regex")
+	cmd.Flags().StringVar(&opts.ExcludeRegex, "exclude-regex", opts.ExcludeRegex, "Never use words matching this regex")
 	cmd.Flags().BoolVar(&opts.Kebab, "kebab", opts.Kebab, "Use kebab-case separators")
 	cmd.Flags().BoolVar(&opts.Snake, "snake", opts.Snake, "Use snake_case separators")
 	cmd.Flags().BoolVar(&opts.Camel, "camel", opts.Camel, "Use camelCase (no separators)")
 	cmd.Flags().IntVar(&opts.Count, "count", opts.Count, "Number of passphrases to generate")
 	cmd.Flags().BoolVar(&opts.JSON, "json", opts.JSON, "Output in JSON format")
 	cmd.Flags().BoolVar(&opts.Copy, "copy", opts.Copy, "Copy result to clipboard")
+	cmd.Flags().BoolVar(&opts.Explain, "explain", opts.Explain,
+		"Show the pattern in DSL form with the entropy of each token instead of generating")
 	cmd.Flags().IntVar(&opts.MinEntropy, "min-entropy", opts.MinEntropy, "Minimum entropy requirement")
 	cmd.Flags().IntVar(&opts.MinLength, "min-length", opts.MinLength, "Minimum length requirement")
+	cmd.Flags().IntVar(&opts.MaxLength, "max-length", opts.MaxLength,
+		"Maximum length; longer passphrases are generated again and the entropy is reduced to match")
+	cmd.Flags().StringVar(&opts.Policy, "policy", opts.Policy,
+		"JSON file of password rules that every result must follow (length, classes, symbols, repeats)")
+
+	cmd.Flags().BoolVar(&opts.ShowRolls, "show-rolls", opts.ShowRolls,
+		"Print the dice rolls of the words picked (diceware dictionaries such as eff only)")
+	cmd.Flags().StringVar(&opts.BreachDB, "avoid-breached", opts.BreachDB,
+		"Pwned Passwords file (HASH:COUNT lines sorted by hash); passphrases found in it are generated again")
+
+	cmd.MarkFlagsMutuallyExclusive("chars", "hex", "mnemonic")
+
+	// A mnemonic is always words from the English BIP39 list, joined by spaces
+	wordFlags := []string{"words", "digits", "symbols", "caps", "sep", "placement", "dict", "kebab", "snake", "camel"}
+
+	for _, flag := range wordFlags {
+		cmd.MarkFlagsMutuallyExclusive("mnemonic", flag)
+	}
 
 	cmd.Flags().SortFlags = false
 
@@ -97,30 +204,88 @@ digits, symbols, and patterns. Output can be plain text or JSON format.`,
 
 // runGenerate executes the passphrase generation.
 func runGenerate(opts *GenOptions) error {
+	dictOpts := DictOptions{
+		Dict:   opts.Dict,
+		Format: opts.DictFormat,
+		Lang:   opts.DictLang,
+		ASCII:  opts.ASCII,
+	}
+
+	filter, err := WordFilterOptions{
+		MinWordLen:   opts.MinWordLen,
+		MaxWordLen:   opts.MaxWordLen,
+		ExcludeWords: opts.ExcludeWords,
+		IncludeRegex: opts.IncludeRegex,
+		ExcludeRegex: opts.ExcludeRegex,
+	}.filter()
+	if err != nil {
+		return err
+	}
+
 	// Get dictionary
-	dict, err := dictionary.GetDictionary(opts.Dict)
+	dict, err := loadDictionary(dictOpts)
 	if err != nil {
-		return fmt.Errorf("loading dictionary: %w", err)
+		return err
 	}
 
 	// Create generator
 	generator := generate.NewGenerator(dict, opts.Sep)
 
-	// Generate passphrases
-	results, err := generator.Generate(generate.Options{
+	// Dictionaries named in the pattern ("W@nouns") get the same ASCII mode
+	generator.SetResolver(func(name string) (dictionary.Dictionary, error) {
+		named := dictOpts
+		named.Dict, named.Format, named.Lang = name, "", ""
+
+		return loadDictionary(named)
+	})
+
+	// The word filters apply to every word, and show in the pattern so that it reproduces them
+	generator.SetWordFilter(filter, opts.ExcludeWords)
+
+	genOpts := generate.Options{
 		Words:      opts.Words,
 		Digits:     opts.Digits,
 		Symbols:    opts.Symbols,
 		Separator:  opts.Sep,
 		Casing:     opts.Caps,
+		Placement:  opts.Placement,
 		Pattern:    opts.Pattern,
+		Chars:      opts.Chars,
+		Hex:        opts.Hex,
+		Mnemonic:   opts.Mnemonic,
 		Kebab:      opts.Kebab,
 		Snake:      opts.Snake,
 		Camel:      opts.Camel,
 		Count:      opts.Count,
 		MinEntropy: opts.MinEntropy,
 		MinLength:  opts.MinLength,
-	})
+		MaxLength:  opts.MaxLength,
+		ShowRolls:  opts.ShowRolls,
+	}
+
+	if opts.Policy != "" {
+		genOpts.Policy, err = generate.LoadPolicy(opts.Policy)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.Explain {
+		return explainPattern(generator, genOpts, opts.JSON)
+	}
+
+	if opts.BreachDB != "" {
+		db, err := breach.Open(opts.BreachDB)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		genOpts.Exclude = db.Contains
+	}
+
+	// Generate passphrases
+	results, err := generator.Generate(genOpts)
 	if err != nil {
 		return fmt.Errorf("generation failed: %w", err)
 	}
@@ -137,9 +302,9 @@ func runGenerate(opts *GenOptions) error {
 	var format string
 
 	if opts.JSON {
-		format = "json"
+		format = formatJSON
 	} else {
-		format = "text"
+		format = formatText
 	}
 
 	formatter := outfmt.NewFormatter(format, os.Stdout, outfmt.Options{
@@ -148,3 +313,22 @@ func runGenerate(opts *GenOptions) error {
 
 	return formatter.FormatResults(results)
 }
+
+// explainPattern prints the pattern the options produce along with the entropy of each token.
+func explainPattern(generator *generate.Generator, genOpts generate.Options, asJSON bool) error {
+	explanation, err := generator.Explain(genOpts)
+	if err != nil {
+		return fmt.Errorf("explaining pattern: %w", err)
+	}
+
+	format := formatText
+	if asJSON {
+		format = formatJSON
+	}
+
+	formatter := outfmt.NewFormatter(format, os.Stdout, outfmt.Options{
+		Colors: !asJSON,
+	})
+
+	return formatter.FormatExplanation(explanation)
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGenRejectsMnemonicWithWordOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "words", args: []string{"--mnemonic", "128", "--words", "5"}},
+		{name: "digits", args: []string{"--mnemonic", "128", "--digits", "2"}},
+		{name: "symbols", args: []string{"--mnemonic", "128", "--symbols", "1"}},
+		{name: "caps", args: []string{"--mnemonic", "128", "--caps", "upper"}},
+		{name: "sep", args: []string{"--mnemonic", "128", "--sep", "."}},
+		{name: "placement", args: []string{"--mnemonic", "128", "--placement", "random"}},
+		{name: "dict", args: []string{"--mnemonic", "128", "--dict", "eff"}},
+		{name: "camel", args: []string{"--mnemonic", "128", "--camel"}},
+		{name: "chars", args: []string{"--mnemonic", "128", "--chars", "16"}},
+		{name: "hex", args: []string{"--mnemonic", "128", "--hex", "32"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := Gen()
+			cmd.SetArgs(test.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			err := cmd.Execute()
+			if err == nil {
+				t.Fatalf("gen %s error = nil, want an error", strings.Join(test.args, " "))
+			}
+
+			if !strings.Contains(err.Error(), "mnemonic") {
+				t.Errorf("gen %s error = %v, want it to name --mnemonic", strings.Join(test.args, " "), err)
+			}
+		})
+	}
+}
@@ -11,10 +11,22 @@ import (
 type Options struct {
 	// Verbose enables verbose output.
 	Verbose bool
+	// Dict is the dictionary used by the interactive TUI.
+	Dict string
+	// DictLang overrides the language of the TUI dictionary.
+	DictLang string
+	// ASCII transliterates the TUI dictionary to ASCII.
+	ASCII bool
+	// MaxLength is the initial maximum passphrase length in the TUI, zero for none.
+	MaxLength int
 }
 
 // Execute runs the root command for the pwgen CLI application.
 func Execute(version string) error {
+	opts := &Options{
+		Dict: "eff",
+	}
+
 	root := &cobra.Command{
 		Use:   "pwgen",
 		Short: "Generate memorable and secure passphrases",
@@ -30,24 +42,42 @@ func Execute(version string) error {
 			# Start interactive TUI mode
 			pwgen
 
+			# Start the TUI with a dictionary from the user dictionary directory
+			pwgen --dict nouns
+
+			# Start the TUI with passphrases of at most 24 characters
+			pwgen --max-length 24
+
 			# Generate a passphrase non-interactively
 			pwgen gen --words 4 --sep "-" --caps mixed
 
 			# Generate with custom pattern
-			pwgen gen --pattern "W:title SEP W:lower SEP DD{2} SEP S"
+			pwgen gen --pattern "W:title SEP W:lower SEP D{2} SEP S"
 
 			# Check entropy of existing passphrase
 			echo "correct-horse-battery-staple" | pwgen check --min-entropy 60
+
+			# Derive a site passphrase from a master passphrase
+			printf '%s\n' "$MASTER" | pwgen derive --site github.com
+
+			# Split a new passphrase into 5 shares, any 3 of which recover it
+			pwgen split --shares 5 --threshold 3
 		`),
 		Version:       version,
 		SilenceErrors: true,
 		SilenceUsage:  true,
 		RunE: func(_ *cobra.Command, _ []string) error {
 			// Default to TUI mode if no subcommand specified
-			return runTUI()
+			return runTUI(opts)
 		},
 	}
 
+	root.Flags().StringVar(&opts.Dict, "dict", opts.Dict,
+		"Dictionary for the interactive TUI: eff|eff-short|eff-short2|bip39-en|cs|de|es|fr|it|name|path")
+	root.Flags().StringVar(&opts.DictLang, "dict-lang", opts.DictLang, "Language of the TUI dictionary for casing rules")
+	root.Flags().BoolVar(&opts.ASCII, "ascii", opts.ASCII, "Transliterate TUI words to ASCII")
+	root.Flags().IntVar(&opts.MaxLength, "max-length", opts.MaxLength, "Initial maximum passphrase length in the TUI")
+
 	root.SetVersionTemplate("{{ .Version }}\n")
 	root.SetHelpCommand(&cobra.Command{Hidden: true})
 
@@ -60,6 +90,10 @@ func Execute(version string) error {
 	root.AddCommand(
 		Gen(),
 		Check(),
+		Derive(),
+		Dice(),
+		Split(),
+		Combine(),
 		Dicts(),
 		Version(),
 	)
@@ -68,6 +102,15 @@ func Execute(version string) error {
 }
 
 // runTUI starts the interactive TUI mode.
-func runTUI() error {
-	return tui.Run()
+func runTUI(opts *Options) error {
+	dict, err := loadDictionary(DictOptions{
+		Dict:  opts.Dict,
+		Lang:  opts.DictLang,
+		ASCII: opts.ASCII,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tui.Run(dict, opts.MaxLength)
 }
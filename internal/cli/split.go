@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/idelchi/pwgen/internal/generate"
+	"github.com/idelchi/pwgen/internal/outfmt"
+	"github.com/idelchi/pwgen/internal/safety"
+	"github.com/idelchi/pwgen/internal/shamir"
+)
+
+// SplitOptions represents the configuration for the split command.
+type SplitOptions struct {
+	Shares     int
+	Threshold  int
+	Stdin      bool
+	Words      int
+	Sep        string
+	Caps       string
+	Digits     int
+	Symbols    int
+	Placement  string
+	Pattern    string
+	Dict       string
+	DictFormat string
+	JSON       bool
+}
+
+// Split returns the split command.
+func Split() *cobra.Command {
+	opts := &SplitOptions{
+		Shares:     5,
+		Threshold:  3,
+		Words:      defaultWordCount,
+		Sep:        "-",
+		Caps:       "mixed",
+		Placement:  "end",
+		Dict:       "eff",
+		DictFormat: "auto",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "split",
+		Short: "Split a passphrase into shares for break-glass recovery",
+		Long: `Generate a passphrase, or read one from stdin, and split it into shares with Shamir's secret sharing.
+
+Any threshold of the shares reconstruct the passphrase with "pwgen combine"; fewer reveal nothing about it.
+Each share is written as words of the dictionary so it can be written down like a passphrase,
+and ends in a checksum that catches most mistyped or missing words.
+
+The same dictionary is needed to combine the shares.`,
+		Example: `  # Generate a passphrase and split it into 5 shares, any 3 of which recover it
+  pwgen split
+
+  # Split an existing passphrase into 3 shares, any 2 of which recover it
+  printf '%s\n' "$SECRET" | pwgen split --stdin --shares 3 --threshold 2
+
+  # Split a generated passphrase, writing the shares with the short word list
+  pwgen split --words 8 --dict eff-short2 --json`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runSplit(opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.Shares, "shares", opts.Shares, "Number of shares to create")
+	cmd.Flags().IntVar(&opts.Threshold, "threshold", opts.Threshold, "Number of shares needed to reconstruct")
+	cmd.Flags().BoolVar(&opts.Stdin, "stdin", opts.Stdin, "Split the passphrase on stdin instead of generating one")
+	cmd.Flags().IntVar(&opts.Words, "words", opts.Words, "Number of words to generate")
+	cmd.Flags().StringVar(&opts.Sep, "sep", opts.Sep, "Separator between tokens")
+	cmd.Flags().StringVar(&opts.Caps, "caps", opts.Caps,
+		"Casing style: mixed|lower|upper|title|alternating|random-title|first-upper|inverted-title")
+	cmd.Flags().IntVar(&opts.Digits, "digits", opts.Digits, "Number of digit tokens")
+	cmd.Flags().IntVar(&opts.Symbols, "symbols", opts.Symbols, "Number of symbol tokens")
+	cmd.Flags().StringVar(&opts.Placement, "placement", opts.Placement,
+		"Where digits and symbols go: end|random (between words)|inside (within words)")
+	cmd.Flags().StringVar(&opts.Pattern, "pattern", opts.Pattern, "Custom pattern (overrides other options)")
+	cmd.Flags().StringVar(&opts.Dict, "dict", opts.Dict,
+		"Dictionary for the passphrase and the shares: eff|eff-short|eff-short2|bip39-en|cs|de|es|fr|it|name|path")
+	cmd.Flags().StringVar(&opts.DictFormat, "dict-format", opts.DictFormat,
+		"Format of a dictionary file: auto|plain|diceware|tsv|csv")
+	cmd.Flags().BoolVar(&opts.JSON, "json", opts.JSON, "Output in JSON format")
+
+	cmd.Flags().SortFlags = false
+
+	return cmd
+}
+
+// runSplit splits the generated or entered passphrase into shares.
+func runSplit(opts *SplitOptions) error {
+	dict, err := loadDictionary(DictOptions{Dict: opts.Dict, Format: opts.DictFormat})
+	if err != nil {
+		return err
+	}
+
+	secret := safety.NewSecureBuffer(0)
+	defer secret.Wipe()
+
+	info := outfmt.SharesInfo{
+		Dictionary: dict.Name(),
+		Threshold:  opts.Threshold,
+	}
+
+	if opts.Stdin {
+		passphrase, err := readStdinLine("passphrase")
+		if err != nil {
+			return err
+		}
+
+		secret.WriteString(passphrase)
+		safety.WipeString(&passphrase)
+	} else {
+		results, err := generate.NewGenerator(dict, opts.Sep).Generate(generate.Options{
+			Words:     opts.Words,
+			Digits:    opts.Digits,
+			Symbols:   opts.Symbols,
+			Separator: opts.Sep,
+			Casing:    opts.Caps,
+			Placement: opts.Placement,
+			Pattern:   opts.Pattern,
+		})
+		if err != nil {
+			return fmt.Errorf("generating passphrase: %w", err)
+		}
+
+		secret.WriteString(results[0].Passphrase)
+
+		info.Passphrase = results[0].Passphrase
+		info.Entropy = results[0].Entropy
+	}
+
+	shares, err := shamir.Split(secret, opts.Shares, opts.Threshold, rand.Reader)
+	if err != nil {
+		return fmt.Errorf("splitting passphrase: %w", err)
+	}
+
+	defer wipeShares(shares)
+
+	for _, share := range shares {
+		words, err := share.Words(dict)
+		if err != nil {
+			return fmt.Errorf("encoding share %d: %w", share.Index, err)
+		}
+
+		info.Shares = append(info.Shares, strings.Join(words, " "))
+	}
+
+	format := formatText
+	if opts.JSON {
+		format = formatJSON
+	}
+
+	formatter := outfmt.NewFormatter(format, os.Stdout, outfmt.Options{
+		Colors: !opts.JSON,
+	})
+
+	return formatter.FormatShares(info)
+}
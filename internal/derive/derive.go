@@ -0,0 +1,198 @@
+// Package derive derives site-specific passphrases from a master passphrase, in the style of LessPass and Spectre.
+//
+// Nothing is stored: the master passphrase, site, counter and pattern are stretched by a memory-hard KDF
+// into a key, and the key is expanded into a deterministic stream that the pattern's tokens draw from
+// instead of crypto/rand. The same inputs and parameters give the same passphrase on any machine.
+//
+// Every detail that affects the output is fixed by the parameter version, so a version's passphrases
+// never change. Changing any of them requires a new version.
+package derive
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/idelchi/pwgen/internal/generate"
+)
+
+const (
+	// KDFArgon2id selects Argon2id, the default.
+	KDFArgon2id = "argon2id"
+	// KDFScrypt selects scrypt.
+	KDFScrypt = "scrypt"
+
+	// LatestVersion is the newest parameter version.
+	LatestVersion = 1
+)
+
+// Params are the versioned derivation parameters.
+type Params struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+
+	// Argon2id cost: passes, memory in KiB and lanes.
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+
+	// scrypt cost: CPU/memory cost N, block size r and parallelism p.
+	N int `json:"n,omitempty"`
+	R int `json:"r,omitempty"`
+	P int `json:"p,omitempty"`
+
+	// KeyLength is the length of the derived key in bytes.
+	KeyLength uint32 `json:"keyLength"`
+}
+
+// Input is what a passphrase is derived from.
+type Input struct {
+	// Master is the master passphrase, NFC-normalized before use.
+	Master string
+	// Site names the service, NFC-normalized, trimmed and lowercased before use,
+	// so "GitHub.com " and "github.com" derive the same passphrase.
+	Site string
+	// Counter is bumped to rotate a site's passphrase.
+	Counter uint32
+}
+
+// ParamsFor returns the parameters of the given version and KDF.
+func ParamsFor(version int, kdf string) (Params, error) {
+	if version != 1 {
+		return Params{}, fmt.Errorf("unknown derivation version %d (latest is %d)", version, LatestVersion)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(kdf)) {
+	case "", KDFArgon2id:
+		return Params{Version: 1, KDF: KDFArgon2id, Time: 3, Memory: 64 * 1024, Threads: 4, KeyLength: 32}, nil
+	case KDFScrypt:
+		return Params{Version: 1, KDF: KDFScrypt, N: 1 << 15, R: 8, P: 1, KeyLength: 32}, nil
+	default:
+		return Params{}, fmt.Errorf("unknown KDF: %q (expected argon2id or scrypt)", kdf)
+	}
+}
+
+// Passphrase derives the passphrase of the pattern for the input.
+func Passphrase(params Params, input Input, pattern *generate.Pattern) (string, error) {
+	key, err := Key(params, input, pattern.String())
+	if err != nil {
+		return "", err
+	}
+
+	passphrase, err := pattern.GenerateFrom(NewStream(params, key))
+	if err != nil {
+		return "", fmt.Errorf("generating from derived key: %w", err)
+	}
+
+	return passphrase, nil
+}
+
+// Key stretches the master passphrase into a key bound to the site, counter and pattern (in DSL form).
+func Key(params Params, input Input, pattern string) ([]byte, error) {
+	if input.Master == "" {
+		return nil, errors.New("master passphrase is empty")
+	}
+
+	site := normalizeSite(input.Site)
+	if site == "" {
+		return nil, errors.New("site is empty")
+	}
+
+	master := []byte(norm.NFC.String(input.Master))
+	salt := Salt(params, site, input.Counter, pattern)
+
+	switch params.KDF {
+	case KDFArgon2id:
+		return argon2.IDKey(master, salt, params.Time, params.Memory, params.Threads, params.KeyLength), nil
+	case KDFScrypt:
+		key, err := scrypt.Key(master, salt, params.N, params.R, params.P, int(params.KeyLength))
+		if err != nil {
+			return nil, fmt.Errorf("running scrypt: %w", err)
+		}
+
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unknown KDF: %q", params.KDF)
+	}
+}
+
+// Salt returns the KDF salt for a normalized site:
+//
+//	"pwgen-derive-v<version>" 0x00 len(site) site counter len(pattern) pattern
+//
+// with lengths and the counter as 4-byte big-endian integers.
+func Salt(params Params, site string, counter uint32, pattern string) []byte {
+	salt := []byte(fmt.Sprintf("pwgen-derive-v%d", params.Version))
+	salt = append(salt, 0)
+	salt = appendField(salt, site)
+	salt = binary.BigEndian.AppendUint32(salt, counter)
+	salt = appendField(salt, pattern)
+
+	return salt
+}
+
+// appendField appends value preceded by its length.
+func appendField(buf []byte, value string) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(value))) //nolint:gosec // sites and patterns are short
+
+	return append(buf, value...)
+}
+
+// normalizeSite returns the canonical form of a site name.
+func normalizeSite(site string) string {
+	return strings.ToLower(strings.TrimSpace(norm.NFC.String(site)))
+}
+
+// Stream is an endless deterministic byte stream expanded from a derived key.
+//
+// Block i is HMAC-SHA256(key, "pwgen-derive-v<version> stream" || i), with i a 8-byte big-endian
+// counter from zero, and the stream is the concatenation of the blocks.
+type Stream struct {
+	label   string
+	key     []byte
+	block   uint64
+	pending []byte
+}
+
+// NewStream returns the stream of a derived key.
+func NewStream(params Params, key []byte) *Stream {
+	return &Stream{
+		label: fmt.Sprintf("pwgen-derive-v%d stream", params.Version),
+		key:   key,
+	}
+}
+
+// Read fills buf with the next bytes of the stream. It never fails.
+func (s *Stream) Read(buf []byte) (int, error) {
+	n := 0
+
+	for n < len(buf) {
+		if len(s.pending) == 0 {
+			s.pending = s.nextBlock()
+		}
+
+		copied := copy(buf[n:], s.pending)
+		s.pending = s.pending[copied:]
+		n += copied
+	}
+
+	return n, nil
+}
+
+// nextBlock computes the next block of the stream.
+func (s *Stream) nextBlock() []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(s.label))
+	mac.Write(binary.BigEndian.AppendUint64(nil, s.block))
+
+	s.block++
+
+	return mac.Sum(nil)
+}
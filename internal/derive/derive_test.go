@@ -0,0 +1,110 @@
+package derive
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+	"github.com/idelchi/pwgen/internal/generate"
+)
+
+// The test vectors of the README's "Version 1" specification.
+const (
+	vectorMaster  = "correct horse battery staple"
+	vectorPattern = `W:mixed SEP("-") W:mixed SEP("-") W:mixed SEP("-") W:mixed`
+)
+
+func TestPassphraseVectors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		site    string
+		counter uint32
+		kdf     string
+		pattern string
+		want    string
+	}{
+		{"github.com", 1, KDFArgon2id, vectorPattern, "BlUff-EXPansIve-sessioNS-porTHolE"},
+		{"github.com", 2, KDFArgon2id, vectorPattern, "HuNdrEDth-CatFIght-oVeRbOok-conCuR"},
+		{"example.org", 1, KDFArgon2id, vectorPattern, "PARtlY-PlAYmaKEr-PretEEN-rEarranGe"},
+		{"github.com", 1, KDFScrypt, vectorPattern, "UNBAkED-expIrInG-MuRkINESs-AudACIouS"},
+		{"example.org", 1, KDFArgon2id, `W:title SEP("-") W:title SEP("-") D{2} S`, "Gravitate-Pretense-94%"},
+		{"github.com", 1, KDFArgon2id, `INSIDE("-", W:mixed W:mixed W:mixed D D S)`, "sTe6ncH-unIVeRse-DiS0Tr.eSS"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.site+"/"+test.kdf+"/"+test.pattern, func(t *testing.T) {
+			t.Parallel()
+
+			params, err := ParamsFor(LatestVersion, test.kdf)
+			if err != nil {
+				t.Fatalf("ParamsFor() error = %v", err)
+			}
+
+			pattern, err := generate.NewPatternBuilder(dictionary.EFF(), "-").BuildFromDSL(test.pattern)
+			if err != nil {
+				t.Fatalf("BuildFromDSL(%q) error = %v", test.pattern, err)
+			}
+
+			if got := pattern.String(); got != test.pattern {
+				t.Fatalf("pattern.String() = %q, want %q", got, test.pattern)
+			}
+
+			got, err := Passphrase(params, Input{Master: vectorMaster, Site: test.site, Counter: test.counter}, pattern)
+			if err != nil {
+				t.Fatalf("Passphrase() error = %v", err)
+			}
+
+			if got != test.want {
+				t.Errorf("Passphrase() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSaltAndKeyVectors(t *testing.T) {
+	t.Parallel()
+
+	const salt = "707767656e2d6465726976652d7631000000000a6769746875622e636f6d000000010000003a573a6d69786564205345502822" +
+		"2d222920573a6d697865642053455028222d222920573a6d697865642053455028222d222920573a6d69786564"
+
+	params, err := ParamsFor(LatestVersion, KDFArgon2id)
+	if err != nil {
+		t.Fatalf("ParamsFor() error = %v", err)
+	}
+
+	if got := hex.EncodeToString(Salt(params, "github.com", 1, vectorPattern)); got != salt {
+		t.Errorf("Salt() = %s, want %s", got, salt)
+	}
+
+	tests := []struct {
+		kdf  string
+		site string
+		want string
+	}{
+		{KDFArgon2id, "github.com", "7e3caba345ad5c3a6e68e300ed1b99f07193827c74ec3baed7b766e08f956260"},
+		{KDFScrypt, "github.com", "a848757dc60a3fb961786415fdfc2c62d4eacda6341ed98a939fd79e706f6045"},
+		// The site is normalized before use.
+		{KDFArgon2id, " GitHub.com ", "7e3caba345ad5c3a6e68e300ed1b99f07193827c74ec3baed7b766e08f956260"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.kdf+"/"+test.site, func(t *testing.T) {
+			t.Parallel()
+
+			params, err := ParamsFor(LatestVersion, test.kdf)
+			if err != nil {
+				t.Fatalf("ParamsFor() error = %v", err)
+			}
+
+			key, err := Key(params, Input{Master: vectorMaster, Site: test.site, Counter: 1}, vectorPattern)
+			if err != nil {
+				t.Fatalf("Key() error = %v", err)
+			}
+
+			if got := hex.EncodeToString(key); got != test.want {
+				t.Errorf("Key() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package dictionary
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiceDigits returns the number of dice rolled per word of dict. The dictionary must have
+// 6^digits words, taken to be in roll order as in a numbered diceware list
+// (the embedded EFF lists and diceware files are).
+func DiceDigits(dict Dictionary) (int, error) {
+	size := dict.Size()
+
+	for digits, rolls := 1, diceSides; digits <= maxDiceDigits; digits, rolls = digits+1, rolls*diceSides {
+		if size == rolls {
+			return digits, nil
+		}
+	}
+
+	return 0, fmt.Errorf("dictionary %q has %d words, which is not a power of %d; use a diceware list such as eff",
+		dict.Name(), size, diceSides)
+}
+
+// DiceIndex converts a diceware roll into its zero-based word index, e.g. "11111" -> 0.
+// It is the inverse of DiceRoll.
+func DiceIndex(roll string, digits int) (int, error) {
+	roll = strings.TrimSpace(roll)
+
+	if len(roll) != digits {
+		return 0, fmt.Errorf("roll %q has %d dice, expected %d", roll, len(roll), digits)
+	}
+
+	index := 0
+
+	for _, r := range roll {
+		if r < '1' || r > '0'+diceSides {
+			return 0, fmt.Errorf("roll %q: %q is not a die face 1-%d", roll, r, diceSides)
+		}
+
+		index = index*diceSides + int(r-'1')
+	}
+
+	return index, nil
+}
+
+// DiceWords returns the words of dict picked by the given rolls.
+func DiceWords(dict Dictionary, rolls []string) ([]string, error) {
+	digits, err := DiceDigits(dict)
+	if err != nil {
+		return nil, err
+	}
+
+	all := dict.Words()
+	words := make([]string, 0, len(rolls))
+
+	for i, roll := range rolls {
+		index, err := DiceIndex(roll, digits)
+		if err != nil {
+			return nil, fmt.Errorf("word %d: %w", i+1, err)
+		}
+
+		words = append(words, all[index])
+	}
+
+	return words, nil
+}
@@ -2,14 +2,16 @@
 package dictionary
 
 import (
-	"bufio"
-	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
 	"math"
-	"math/big"
 	"os"
-	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/idelchi/pwgen/internal/random"
 )
 
 // Dictionary represents a word dictionary for passphrase generation.
@@ -23,64 +25,97 @@ type Dictionary interface {
 	// Words returns all words in the dictionary.
 	Words() []string
 
-	// RandomWord returns a cryptographically random word from the dictionary.
-	RandomWord() (string, error)
+	// RandomWord returns a random word from the dictionary, drawing randomness from source
+	// (crypto/rand.Reader for cryptographically random words).
+	RandomWord(source io.Reader) (string, error)
 
 	// EntropyBits returns the entropy bits per word for this dictionary.
 	EntropyBits() float64
+
+	// Language returns the language of the words, which drives casing rules.
+	// It is language.Und when unknown.
+	Language() language.Tag
 }
 
 // wordDict implements Dictionary with a slice of words.
 type wordDict struct {
 	name  string
+	lang  language.Tag
 	words []string
 }
 
-// NewFromWords creates a new dictionary from a slice of words.
+// NewFromWords creates a new dictionary from a slice of words in an unspecified language.
 //
 //nolint:ireturn // Dictionary interface is the intended public API for polymorphism
 func NewFromWords(name string, words []string) Dictionary {
+	return NewFromWordsLanguage(name, language.Und, words)
+}
+
+// NewFromWordsLanguage creates a new dictionary from a slice of words in the given language.
+// Words are normalized to Unicode NFC. Empty words and words that collide with an
+// earlier word once casing is applied are dropped, so every word is equally likely
+// and the entropy is not overstated. Use Validate to report such problems.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func NewFromWordsLanguage(name string, lang language.Tag, words []string) Dictionary {
 	// Create a copy to avoid external modification.
-	wordsCopy := make([]string, len(words))
-	copy(wordsCopy, words)
+	wordsCopy := make([]string, 0, len(words))
+	seen := make(map[string]bool, len(words))
+
+	for _, word := range words {
+		word = norm.NFC.String(word)
+
+		key := foldWord(word)
+		if word == "" || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		wordsCopy = append(wordsCopy, word)
+	}
 
 	return &wordDict{
 		name:  name,
+		lang:  lang,
 		words: wordsCopy,
 	}
 }
 
 // NewFromFile creates a new dictionary from a file path.
-// The file should contain one word per line.
+// The layout (plain, diceware, TSV or CSV) is detected from the file contents.
 //
 //nolint:ireturn // Dictionary interface is the intended public API for polymorphism
 func NewFromFile(path string) (Dictionary, error) {
-	file, err := os.Open(path) //nolint:gosec // User-provided file path is intentional
+	return NewFromFileFormat(path, FormatAuto)
+}
+
+// NewFromFileFormat creates a new dictionary from a file in the given format.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func NewFromFileFormat(path string, format Format) (Dictionary, error) {
+	words, _, err := readWordsFile(path, format)
 	if err != nil {
-		return nil, fmt.Errorf("opening dictionary file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
-
-	var words []string
-
-	scanner := bufio.NewScanner(file)
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" && !strings.HasPrefix(line, "#") {
-			words = append(words, line)
-		}
-	}
+	return NewFromWords(path, words), nil
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("reading dictionary file: %w", err)
+// readWordsFile reads the raw word list of a file in the given format,
+// together with the number of blank lines it skipped.
+func readWordsFile(path string, format Format) ([]string, int, error) {
+	file, err := os.Open(path) //nolint:gosec // User-provided file path is intentional
+	if err != nil {
+		return nil, 0, fmt.Errorf("opening dictionary file: %w", err)
 	}
+	defer file.Close()
 
-	if len(words) == 0 {
-		return nil, errors.New("dictionary file contains no words")
+	words, blank, err := parseWords(file, format)
+	if err != nil {
+		return nil, 0, fmt.Errorf("parsing dictionary file %q: %w", path, err)
 	}
 
-	return NewFromWords(path, words), nil
+	return words, blank, nil
 }
 
 // Name returns the name of this dictionary.
@@ -102,21 +137,18 @@ func (d *wordDict) Words() []string {
 	return result
 }
 
-// RandomWord returns a cryptographically random word from the dictionary.
-func (d *wordDict) RandomWord() (string, error) {
+// RandomWord returns a uniformly random word from the dictionary, drawing randomness from source.
+func (d *wordDict) RandomWord(source io.Reader) (string, error) {
 	if len(d.words) == 0 {
 		return "", errors.New("dictionary is empty")
 	}
 
-	// Use uniform distribution to avoid modulo bias.
-	maxVal := big.NewInt(int64(len(d.words)))
-
-	n, err := rand.Int(rand.Reader, maxVal)
+	n, err := random.Int(source, len(d.words))
 	if err != nil {
 		return "", fmt.Errorf("generating random number: %w", err)
 	}
 
-	return d.words[n.Int64()], nil
+	return d.words[n], nil
 }
 
 // EntropyBits returns the entropy bits per word for this dictionary.
@@ -127,3 +159,8 @@ func (d *wordDict) EntropyBits() float64 {
 	// log2(dictionary_size)
 	return math.Log2(float64(len(d.words)))
 }
+
+// Language returns the language of the words in this dictionary.
+func (d *wordDict) Language() language.Tag {
+	return d.lang
+}
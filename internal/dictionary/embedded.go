@@ -0,0 +1,155 @@
+package dictionary
+
+import (
+	"embed"
+	"fmt"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	// effLargeSize is the size of the EFF Large Wordlist (6^5).
+	effLargeSize = 7776
+	// effShortSize is the size of the EFF Short Wordlists (6^4).
+	effShortSize = 1296
+	// bip39Size is the size of the BIP39 wordlists (2^11).
+	bip39Size = 2048
+	// germanSize is the size of the German wordlist, that of the BIP39 lists (2^11).
+	germanSize = 2048
+)
+
+// wordlists holds the word lists shipped inside the binary.
+//
+//go:embed wordlists/*.txt
+var wordlists embed.FS
+
+// Embedded word lists, parsed once on first use.
+//
+//nolint:gochecknoglobals // Lazily parsed, read-only embedded word lists
+var (
+	effLargeWords = sync.OnceValue(func() []string {
+		return mustLoadEmbedded("wordlists/eff_large_wordlist.txt", FormatDiceware, effLargeSize)
+	})
+	effShortWords = sync.OnceValue(func() []string {
+		return mustLoadEmbedded("wordlists/eff_short_wordlist_1.txt", FormatDiceware, effShortSize)
+	})
+	effShort2Words = sync.OnceValue(func() []string {
+		return mustLoadEmbedded("wordlists/eff_short_wordlist_2_0.txt", FormatDiceware, effShortSize)
+	})
+	englishBIP39Words = sync.OnceValue(func() []string {
+		return mustLoadEmbedded("wordlists/bip39_english.txt", FormatPlain, bip39Size)
+	})
+	czechWords = sync.OnceValue(func() []string {
+		return mustLoadEmbedded("wordlists/bip39_czech.txt", FormatPlain, bip39Size)
+	})
+	germanWords = sync.OnceValue(func() []string {
+		return mustLoadEmbedded("wordlists/german.txt", FormatPlain, germanSize)
+	})
+	frenchWords = sync.OnceValue(func() []string {
+		return mustLoadEmbedded("wordlists/bip39_french.txt", FormatPlain, bip39Size)
+	})
+	italianWords = sync.OnceValue(func() []string {
+		return mustLoadEmbedded("wordlists/bip39_italian.txt", FormatPlain, bip39Size)
+	})
+	spanishWords = sync.OnceValue(func() []string {
+		return mustLoadEmbedded("wordlists/bip39_spanish.txt", FormatPlain, bip39Size)
+	})
+)
+
+// EFF returns the EFF Large Wordlist (7776 words, five dice per word).
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func EFF() Dictionary {
+	return newShared("eff", language.English, effLargeWords())
+}
+
+// EFFShort returns the EFF Short Wordlist 1 (1296 words, four dice per word).
+// Its words are at most five letters long.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func EFFShort() Dictionary {
+	return newShared("eff-short", language.English, effShortWords())
+}
+
+// EFFShort2 returns the EFF Short Wordlist 2.0 (1296 words, four dice per word).
+// Its words have unique three-character prefixes and are easier to type.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func EFFShort2() Dictionary {
+	return newShared("eff-short2", language.English, effShort2Words())
+}
+
+// BIP39English returns the English BIP39 wordlist (2048 words) in its standard order,
+// which BIP39 mnemonics index. Its words have unique four-letter prefixes.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func BIP39English() Dictionary {
+	return newShared("bip39-en", language.English, englishBIP39Words())
+}
+
+// Czech returns the Czech BIP39 wordlist (2048 words).
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func Czech() Dictionary {
+	return newShared("cs", language.Czech, czechWords())
+}
+
+// German returns the German wordlist (2048 common words, with umlauts and ß).
+// Nouns are lowercase like the other words, so casing is applied uniformly.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func German() Dictionary {
+	return newShared("de", language.German, germanWords())
+}
+
+// French returns the French BIP39 wordlist (2048 words, with accents).
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func French() Dictionary {
+	return newShared("fr", language.French, frenchWords())
+}
+
+// Italian returns the Italian BIP39 wordlist (2048 words).
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func Italian() Dictionary {
+	return newShared("it", language.Italian, italianWords())
+}
+
+// Spanish returns the Spanish BIP39 wordlist (2048 words, with accents).
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func Spanish() Dictionary {
+	return newShared("es", language.Spanish, spanishWords())
+}
+
+// newShared creates a dictionary backed by an immutable word slice without copying it.
+func newShared(name string, lang language.Tag, words []string) *wordDict {
+	return &wordDict{
+		name:  name,
+		lang:  lang,
+		words: words,
+	}
+}
+
+// mustLoadEmbedded reads an embedded word list; diceware lists are ordered by dice roll.
+// The embedded files are part of the build, so a malformed file is a programming error.
+func mustLoadEmbedded(path string, format Format, size int) []string {
+	file, err := wordlists.Open(path)
+	if err != nil {
+		panic(fmt.Sprintf("opening embedded word list %q: %v", path, err))
+	}
+	defer file.Close()
+
+	words, err := ParseWords(file, format)
+	if err != nil {
+		panic(fmt.Sprintf("parsing embedded word list %q: %v", path, err))
+	}
+
+	if len(words) != size {
+		panic(fmt.Sprintf("embedded word list %q has %d words, expected %d", path, len(words), size))
+	}
+
+	return words
+}
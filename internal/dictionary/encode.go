@@ -0,0 +1,108 @@
+package dictionary
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// encodingMarker is prepended to encoded data so that leading zero bytes survive the round trip.
+const encodingMarker = 0x01
+
+// EncodeBytes writes data as words of dict, as the digits of a number in base dict.Size(),
+// most significant first. DecodeWords reverses it with the same dictionary.
+func EncodeBytes(dict Dictionary, data []byte) ([]string, error) {
+	words, err := encodingWords(dict)
+	if err != nil {
+		return nil, err
+	}
+
+	marked := append([]byte{encodingMarker}, data...)
+	value := new(big.Int).SetBytes(marked)
+
+	defer clear(marked)
+	defer wipeInt(value)
+
+	base := big.NewInt(int64(len(words)))
+	digit := new(big.Int)
+
+	var encoded []string
+
+	for value.Sign() > 0 {
+		value.QuoRem(value, base, digit)
+		encoded = append(encoded, words[digit.Int64()])
+	}
+
+	// Most significant digit first
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+
+	return encoded, nil
+}
+
+// DecodeWords reads the data written by EncodeBytes with the same dictionary.
+// Words are matched regardless of case. The caller should wipe the returned bytes.
+func DecodeWords(dict Dictionary, encoded []string) ([]byte, error) {
+	words, err := encodingWords(dict)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]int64, len(words))
+
+	for i, word := range words {
+		positions[foldWord(word)] = int64(i)
+	}
+
+	value := new(big.Int)
+	defer wipeInt(value)
+
+	base := big.NewInt(int64(len(words)))
+
+	for i, word := range encoded {
+		position, ok := positions[foldWord(word)]
+		if !ok {
+			return nil, fmt.Errorf("word %d (%q) is not in dictionary %q", i+1, word, dict.Name())
+		}
+
+		value.Mul(value, base)
+		value.Add(value, big.NewInt(position))
+	}
+
+	data := value.Bytes()
+
+	if len(data) == 0 || data[0] != encodingMarker {
+		clear(data)
+
+		return nil, errors.New("the words do not encode any data; check for missing or extra words")
+	}
+
+	return data[1:], nil
+}
+
+// encodingWords returns the words of dict, which must be able to encode data.
+func encodingWords(dict Dictionary) ([]string, error) {
+	words := dict.Words()
+
+	if len(words) < 2 {
+		return nil, fmt.Errorf("dictionary %q needs at least 2 words to encode data", dict.Name())
+	}
+
+	for _, word := range words {
+		if strings.IndexFunc(word, unicode.IsSpace) >= 0 {
+			return nil, fmt.Errorf("dictionary %q has words with spaces, such as %q, which cannot encode data",
+				dict.Name(), word)
+		}
+	}
+
+	return words, nil
+}
+
+// wipeInt clears the memory behind value, as far as math/big exposes it.
+func wipeInt(value *big.Int) {
+	bits := value.Bits()
+	clear(bits[:cap(bits)])
+}
@@ -0,0 +1,87 @@
+package dictionary
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// FilterOptions constrains which words of a dictionary may be used.
+// Zero values disable the respective constraint.
+type FilterOptions struct {
+	// MinLength is the minimum word length in characters.
+	MinLength int
+	// MaxLength is the maximum word length in characters.
+	MaxLength int
+	// Include keeps only words matching the expression.
+	Include *regexp.Regexp
+	// Exclude drops words matching the expression.
+	Exclude *regexp.Regexp
+	// Blocklist drops the listed words, compared case-insensitively.
+	Blocklist []string
+}
+
+// IsZero reports whether the options filter nothing.
+func (o FilterOptions) IsZero() bool {
+	return o.MinLength <= 0 && o.MaxLength <= 0 && o.Include == nil && o.Exclude == nil && len(o.Blocklist) == 0
+}
+
+// Filter returns a dictionary holding only the words of dict that satisfy opts.
+// Size and EntropyBits of the result describe the reduced list.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func Filter(dict Dictionary, opts FilterOptions) (Dictionary, error) {
+	if opts.IsZero() {
+		return dict, nil
+	}
+
+	if opts.MaxLength > 0 && opts.MinLength > opts.MaxLength {
+		return nil, fmt.Errorf("minimum word length %d exceeds maximum %d", opts.MinLength, opts.MaxLength)
+	}
+
+	blocked := make(map[string]bool, len(opts.Blocklist))
+
+	for _, word := range opts.Blocklist {
+		blocked[foldWord(word)] = true
+	}
+
+	words := dict.Words()
+	kept := make([]string, 0, len(words))
+
+	for _, word := range words {
+		length := utf8.RuneCountInString(word)
+
+		switch {
+		case opts.MinLength > 0 && length < opts.MinLength,
+			opts.MaxLength > 0 && length > opts.MaxLength,
+			opts.Include != nil && !opts.Include.MatchString(word),
+			opts.Exclude != nil && opts.Exclude.MatchString(word),
+			blocked[foldWord(word)]:
+			continue
+		}
+
+		kept = append(kept, word)
+	}
+
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("no words in dictionary %q satisfy the filter", dict.Name())
+	}
+
+	return NewFromWordsLanguage(dict.Name(), dict.Language(), kept), nil
+}
+
+// LoadBlocklist reads a list of words to exclude, one per line.
+// Empty lines and lines starting with "#" are ignored.
+func LoadBlocklist(path string) ([]string, error) {
+	words, _, err := readWordsFile(path, FormatPlain)
+	if errors.Is(err, errNoWords) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("loading blocklist: %w", err)
+	}
+
+	return words, nil
+}
@@ -0,0 +1,325 @@
+package dictionary
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// errNoWords is returned when a word list file has no words.
+var errNoWords = errors.New("dictionary file contains no words")
+
+// Format identifies the layout of a word list file.
+type Format int
+
+const (
+	// FormatAuto detects the layout from the first entry of the file.
+	FormatAuto Format = iota
+	// FormatPlain is one word per line.
+	FormatPlain
+	// FormatDiceware is a numbered diceware list ("11111<whitespace>word" per line).
+	FormatDiceware
+	// FormatTSV is a tab-separated list with a word column.
+	FormatTSV
+	// FormatCSV is a comma-separated list with a word column.
+	FormatCSV
+)
+
+const (
+	// diceSides is the number of sides of a standard die.
+	diceSides = 6
+	// maxDiceDigits is the largest supported number of dice per word.
+	maxDiceDigits = 8
+	// wordColumnHeader is the header name that marks the word column in TSV/CSV lists.
+	wordColumnHeader = "word"
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatAuto:
+		return "auto"
+	case FormatPlain:
+		return "plain"
+	case FormatDiceware:
+		return "diceware"
+	case FormatTSV:
+		return "tsv"
+	case FormatCSV:
+		return "csv"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseFormat converts a string to a Format.
+func ParseFormat(str string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "", "auto":
+		return FormatAuto, nil
+	case "plain":
+		return FormatPlain, nil
+	case "diceware":
+		return FormatDiceware, nil
+	case "tsv":
+		return FormatTSV, nil
+	case "csv":
+		return FormatCSV, nil
+	default:
+		return FormatAuto, fmt.Errorf("unknown dictionary format: %q", str)
+	}
+}
+
+// entry is a non-empty, non-comment line of a word list file.
+type entry struct {
+	line int
+	text string
+}
+
+// ParseWords reads a word list in the given format.
+// Empty lines and lines starting with "#" are ignored, and lines are normalized to Unicode NFC.
+// Diceware lists are returned ordered by their dice rolls.
+func ParseWords(reader io.Reader, format Format) ([]string, error) {
+	words, _, err := parseWords(reader, format)
+
+	return words, err
+}
+
+// parseWords is ParseWords that also returns the number of blank lines it ignored.
+func parseWords(reader io.Reader, format Format) ([]string, int, error) {
+	entries, blank, err := readEntries(reader)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(entries) == 0 {
+		return nil, 0, errNoWords
+	}
+
+	if format == FormatAuto {
+		format = detectFormat(entries[0].text)
+	}
+
+	var words []string
+
+	switch format {
+	case FormatPlain:
+		words = parsePlain(entries)
+	case FormatDiceware:
+		words, err = parseDiceware(entries)
+	case FormatTSV:
+		words, err = parseColumns(entries, '\t')
+	case FormatCSV:
+		words, err = parseColumns(entries, ',')
+	default:
+		err = fmt.Errorf("unsupported dictionary format: %s", format)
+	}
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return words, blank, nil
+}
+
+// readEntries collects the meaningful lines of a word list, together with the number of blank lines.
+func readEntries(reader io.Reader) ([]entry, int, error) {
+	var entries []entry
+
+	scanner := bufio.NewScanner(reader)
+	lineNo, blank := 0, 0
+
+	for scanner.Scan() {
+		lineNo++
+
+		line := norm.NFC.String(strings.TrimSpace(scanner.Text()))
+
+		switch {
+		case line == "":
+			blank++
+		case !strings.HasPrefix(line, "#"):
+			entries = append(entries, entry{line: lineNo, text: line})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("reading dictionary file: %w", err)
+	}
+
+	return entries, blank, nil
+}
+
+// detectFormat guesses the format from the first entry of a word list.
+func detectFormat(first string) Format {
+	if rolls, _, ok := splitDiceware(first); ok && isDiceRoll(rolls) {
+		return FormatDiceware
+	}
+
+	switch {
+	case strings.Contains(first, "\t"):
+		return FormatTSV
+	case strings.Contains(first, ","):
+		return FormatCSV
+	default:
+		return FormatPlain
+	}
+}
+
+// parsePlain treats every entry as a word.
+func parsePlain(entries []entry) []string {
+	words := make([]string, len(entries))
+
+	for i, e := range entries {
+		words[i] = e.text
+	}
+
+	return words
+}
+
+// splitDiceware splits a diceware line into its roll number and word.
+func splitDiceware(line string) (string, string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 { //nolint:mnd // roll and word
+		return "", "", false
+	}
+
+	return fields[0], fields[1], true
+}
+
+// isDiceRoll reports whether str consists only of die faces 1-6.
+func isDiceRoll(str string) bool {
+	if str == "" || len(str) > maxDiceDigits {
+		return false
+	}
+
+	for _, r := range str {
+		if r < '1' || r > '0'+diceSides {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseDiceware parses a numbered diceware list and verifies that every
+// possible roll for the list's dice count appears exactly once.
+func parseDiceware(entries []entry) ([]string, error) {
+	digits := 0
+	byRoll := make(map[string]string, len(entries))
+
+	for _, e := range entries {
+		roll, word, ok := splitDiceware(e.text)
+		if !ok || !isDiceRoll(roll) {
+			return nil, fmt.Errorf("line %d: expected \"<dice roll> <word>\", got %q", e.line, e.text)
+		}
+
+		if digits == 0 {
+			digits = len(roll)
+		}
+
+		if len(roll) != digits {
+			return nil, fmt.Errorf("line %d: roll %s has %d dice, expected %d", e.line, roll, len(roll), digits)
+		}
+
+		if _, exists := byRoll[roll]; exists {
+			return nil, fmt.Errorf("line %d: duplicate roll %s", e.line, roll)
+		}
+
+		byRoll[roll] = word
+	}
+
+	expected := int(math.Pow(diceSides, float64(digits)))
+	words := make([]string, 0, expected)
+
+	for index := range expected {
+		roll := DiceRoll(index, digits)
+
+		word, exists := byRoll[roll]
+		if !exists {
+			return nil, fmt.Errorf("diceware list is incomplete: missing roll %s (%d of %d rolls present)",
+				roll, len(byRoll), expected)
+		}
+
+		words = append(words, word)
+	}
+
+	return words, nil
+}
+
+// DiceRoll converts a zero-based word index into its diceware roll, e.g. 0 -> "11111".
+func DiceRoll(index, digits int) string {
+	roll := make([]byte, digits)
+
+	for i := digits - 1; i >= 0; i-- {
+		roll[i] = byte('1' + index%diceSides)
+		index /= diceSides
+	}
+
+	return string(roll)
+}
+
+// parseColumns parses a delimited list, taking words from the column headed
+// "word" or, without a header, from the first non-numeric column.
+func parseColumns(entries []entry, delimiter rune) ([]string, error) {
+	records := make([][]string, len(entries))
+
+	for i, e := range entries {
+		reader := csv.NewReader(strings.NewReader(e.text))
+		reader.Comma = delimiter
+		reader.LazyQuotes = true
+
+		record, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", e.line, err)
+		}
+
+		records[i] = record
+	}
+
+	column := slices.IndexFunc(records[0], func(field string) bool {
+		return strings.EqualFold(strings.TrimSpace(field), wordColumnHeader)
+	})
+
+	if column >= 0 {
+		records = records[1:]
+		entries = entries[1:]
+	} else {
+		column = slices.IndexFunc(records[0], func(field string) bool {
+			_, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+
+			return err != nil
+		})
+	}
+
+	if column < 0 {
+		return nil, fmt.Errorf("line %d: no word column found", entries[0].line)
+	}
+
+	words := make([]string, 0, len(records))
+
+	for i, record := range records {
+		if column >= len(record) {
+			return nil, fmt.Errorf("line %d: missing word column %d", entries[i].line, column+1)
+		}
+
+		word := strings.TrimSpace(record[column])
+		if word == "" {
+			return nil, fmt.Errorf("line %d: empty word", entries[i].line)
+		}
+
+		words = append(words, word)
+	}
+
+	if len(words) == 0 {
+		return nil, errNoWords
+	}
+
+	return words, nil
+}
@@ -0,0 +1,55 @@
+package dictionary
+
+import "sync"
+
+const (
+	// Sizes of the embedded frequency lists.
+	passwordsFrequencySize   = 7141
+	englishFrequencySize     = 30000
+	femaleNamesFrequencySize = 3815
+	maleNamesFrequencySize   = 1004
+	surnamesFrequencySize    = 10000
+)
+
+// RankedList is a word list ordered from the most to the least common word.
+type RankedList struct {
+	// Name identifies the list, e.g. "passwords".
+	Name string
+	// Words are lowercase, the most common first.
+	Words []string
+}
+
+// frequencyLists are the embedded frequency lists, parsed once on first use.
+//
+//nolint:gochecknoglobals // Lazily parsed, read-only embedded word lists
+var frequencyLists = sync.OnceValue(func() []RankedList {
+	return []RankedList{
+		{
+			Name:  "passwords",
+			Words: mustLoadEmbedded("wordlists/freq_passwords.txt", FormatPlain, passwordsFrequencySize),
+		},
+		{
+			Name:  "english",
+			Words: mustLoadEmbedded("wordlists/freq_english.txt", FormatPlain, englishFrequencySize),
+		},
+		{
+			Name:  "female_names",
+			Words: mustLoadEmbedded("wordlists/freq_female_names.txt", FormatPlain, femaleNamesFrequencySize),
+		},
+		{
+			Name:  "male_names",
+			Words: mustLoadEmbedded("wordlists/freq_male_names.txt", FormatPlain, maleNamesFrequencySize),
+		},
+		{
+			Name:  "surnames",
+			Words: mustLoadEmbedded("wordlists/freq_surnames.txt", FormatPlain, surnamesFrequencySize),
+		},
+	}
+})
+
+// FrequencyLists returns the embedded frequency lists used to estimate how guessable a password is:
+// common passwords, English words, and first names and surnames.
+// They come from zxcvbn (MIT, see wordlists/LICENSE-zxcvbn). The lists are shared and must not be modified.
+func FrequencyLists() []RankedList {
+	return frequencyLists()
+}
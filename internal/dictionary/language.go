@@ -0,0 +1,76 @@
+package dictionary
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// asciiReplacements maps letters that do not decompose into an ASCII base letter.
+//
+//nolint:gochecknoglobals // Read-only transliteration table
+var asciiReplacements = strings.NewReplacer(
+	"ß", "ss", "ẞ", "SS",
+	"æ", "ae", "Æ", "AE",
+	"œ", "oe", "Œ", "OE",
+	"ø", "o", "Ø", "O",
+	"ł", "l", "Ł", "L",
+	"đ", "d", "Đ", "D",
+	"ð", "d", "Ð", "D",
+	"þ", "th", "Þ", "TH",
+	"ı", "i",
+)
+
+// WithLanguage returns a copy of dict whose words are cased by the rules of lang.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func WithLanguage(dict Dictionary, lang language.Tag) Dictionary {
+	return NewFromWordsLanguage(dict.Name(), lang, dict.Words())
+}
+
+// ASCII returns a copy of dict with every word transliterated to ASCII,
+// for systems that reject non-ASCII input. Words that cannot be transliterated
+// are dropped, and words that become identical are merged, so Size and
+// EntropyBits describe the reduced list.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func ASCII(dict Dictionary) Dictionary {
+	words := dict.Words()
+	ascii := make([]string, 0, len(words))
+
+	for _, word := range words {
+		if word, ok := Transliterate(word); ok {
+			ascii = append(ascii, word)
+		}
+	}
+
+	// Language-specific casing could reintroduce non-ASCII letters (e.g. Turkish "İ").
+	return NewFromWordsLanguage(dict.Name(), language.Und, ascii)
+}
+
+// Transliterate strips accents and replaces special letters so that word is ASCII,
+// e.g. "Übung" becomes "Ubung" and "Straße" becomes "Strasse".
+// It reports false if non-ASCII characters remain.
+func Transliterate(word string) (string, bool) {
+	decomposed := norm.NFD.String(asciiReplacements.Replace(word))
+
+	var result strings.Builder
+
+	result.Grow(len(decomposed))
+
+	for _, r := range decomposed {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		if r > unicode.MaxASCII {
+			return "", false
+		}
+
+		result.WriteRune(r)
+	}
+
+	return result.String(), true
+}
@@ -3,51 +3,71 @@ package dictionary
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
 )
 
 // BuiltinDict represents information about a built-in dictionary.
 type BuiltinDict struct {
 	Name        string
 	Description string
+	Language    language.Tag
 	WordCount   int
 	Factory     func() Dictionary
 }
 
-// builtinDictionaries contains all available built-in dictionaries.
-//
-//nolint:gochecknoglobals // Package-level registry for built-in dictionaries
-var builtinDictionaries = map[string]BuiltinDict{
-	"eff": {
-		Name:        "eff",
-		Description: "EFF Large Wordlist - 7776 cryptographically secure diceware words",
-		WordCount:   0, // Will be set during initialization
-		Factory:     ExternalEFF,
-	},
+// Registry is a concurrency-safe set of named dictionaries.
+type Registry struct {
+	mu    sync.RWMutex
+	dicts map[string]BuiltinDict
 }
 
-// initializeWordCounts initializes word counts for built-in dictionaries on first access.
-func initializeWordCounts() {
-	for name, info := range builtinDictionaries {
-		if info.WordCount == 0 {
-			dict := info.Factory()
+// NewRegistry creates an empty dictionary registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		dicts: make(map[string]BuiltinDict),
+	}
+}
 
-			info.WordCount = dict.Size()
-			builtinDictionaries[name] = info
-		}
+// Register adds a dictionary to the registry.
+// Names are case-insensitive and must be unique. A zero WordCount is filled in
+// from the dictionary's size the first time the registry is listed.
+func (r *Registry) Register(info BuiltinDict) error {
+	info.Name = strings.ToLower(strings.TrimSpace(info.Name))
+
+	if info.Name == "" {
+		return errors.New("dictionary name cannot be empty")
+	}
+
+	if info.Factory == nil {
+		return fmt.Errorf("dictionary %q has no factory", info.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.dicts[info.Name]; exists {
+		return fmt.Errorf("dictionary %q is already registered", info.Name)
 	}
+
+	r.dicts[info.Name] = info
+
+	return nil
 }
 
-// GetBuiltin returns a built-in dictionary by name.
-// Supported names: "eff".
+// Get returns a registered dictionary by name.
 //
 //nolint:ireturn // Dictionary interface is the intended public API for polymorphism
-func GetBuiltin(name string) (Dictionary, error) {
-	initializeWordCounts()
-
+func (r *Registry) Get(name string) (Dictionary, error) {
 	name = strings.ToLower(strings.TrimSpace(name))
 
-	info, exists := builtinDictionaries[name]
+	r.mu.RLock()
+	info, exists := r.dicts[name]
+	r.mu.RUnlock()
+
 	if !exists {
 		return nil, fmt.Errorf("unknown built-in dictionary: %q", name)
 	}
@@ -55,26 +75,156 @@ func GetBuiltin(name string) (Dictionary, error) {
 	return info.Factory(), nil
 }
 
-// ListBuiltin returns information about all built-in dictionaries.
-func ListBuiltin() []BuiltinDict {
-	initializeWordCounts()
+// Has reports whether a dictionary with the given name is registered.
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	result := make([]BuiltinDict, 0, len(builtinDictionaries))
+	_, exists := r.dicts[strings.ToLower(strings.TrimSpace(name))]
+
+	return exists
+}
+
+// List returns information about all registered dictionaries, sorted by name.
+func (r *Registry) List() []BuiltinDict {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]BuiltinDict, 0, len(r.dicts))
+
+	for name, info := range r.dicts {
+		if info.WordCount == 0 {
+			info.WordCount = info.Factory().Size()
+			r.dicts[name] = info
+		}
 
-	for _, info := range builtinDictionaries {
 		result = append(result, info)
 	}
 
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
 	return result
 }
 
+// builtins is the registry used by the package-level functions.
+//
+//nolint:gochecknoglobals // Package-level registry for built-in dictionaries
+var builtins = newBuiltinRegistry()
+
+// newBuiltinRegistry creates a registry holding the dictionaries shipped with pwgen.
+func newBuiltinRegistry() *Registry {
+	registry := NewRegistry()
+
+	for _, info := range []BuiltinDict{
+		{
+			Name:        "eff",
+			Description: "EFF Large Wordlist - 7776 diceware words (five dice per word)",
+			Language:    language.English,
+			WordCount:   effLargeSize,
+			Factory:     EFF,
+		},
+		{
+			Name:        "eff-short",
+			Description: "EFF Short Wordlist 1 - 1296 short words of at most 5 letters (four dice per word)",
+			Language:    language.English,
+			WordCount:   effShortSize,
+			Factory:     EFFShort,
+		},
+		{
+			Name:        "eff-short2",
+			Description: "EFF Short Wordlist 2.0 - 1296 words with unique 3-letter prefixes (four dice per word)",
+			Language:    language.English,
+			WordCount:   effShortSize,
+			Factory:     EFFShort2,
+		},
+		{
+			Name:        "bip39-en",
+			Description: "English BIP39 wordlist - 2048 words, used for BIP39 mnemonics",
+			Language:    language.English,
+			WordCount:   bip39Size,
+			Factory:     BIP39English,
+		},
+		{
+			Name:        "cs",
+			Description: "Czech BIP39 wordlist - 2048 words",
+			Language:    language.Czech,
+			WordCount:   bip39Size,
+			Factory:     Czech,
+		},
+		{
+			Name:        "de",
+			Description: "German wordlist - 2048 common words, with umlauts",
+			Language:    language.German,
+			WordCount:   germanSize,
+			Factory:     German,
+		},
+		{
+			Name:        "es",
+			Description: "Spanish BIP39 wordlist - 2048 words",
+			Language:    language.Spanish,
+			WordCount:   bip39Size,
+			Factory:     Spanish,
+		},
+		{
+			Name:        "fr",
+			Description: "French BIP39 wordlist - 2048 words",
+			Language:    language.French,
+			WordCount:   bip39Size,
+			Factory:     French,
+		},
+		{
+			Name:        "it",
+			Description: "Italian BIP39 wordlist - 2048 words",
+			Language:    language.Italian,
+			WordCount:   bip39Size,
+			Factory:     Italian,
+		},
+	} {
+		if err := registry.Register(info); err != nil {
+			panic(err)
+		}
+	}
+
+	return registry
+}
+
+// Register adds a dictionary to the built-in registry, making it available
+// to GetBuiltin, GetDictionary and ListBuiltin. It is safe for concurrent use.
+func Register(info BuiltinDict) error {
+	return builtins.Register(info)
+}
+
+// GetBuiltin returns a built-in dictionary by name.
+// Supported names: "eff", "eff-short", "eff-short2", "bip39-en", "cs", "de", "es", "fr", "it"
+// and any dictionary added with Register.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func GetBuiltin(name string) (Dictionary, error) {
+	return builtins.Get(name)
+}
+
+// ListBuiltin returns information about all built-in dictionaries, sorted by name.
+func ListBuiltin() []BuiltinDict {
+	return builtins.List()
+}
+
 // GetDictionary returns a dictionary from a source specification.
 // Source can be:
-// - "eff" or "small" for built-in dictionaries
+// - The name of a built-in dictionary, e.g. "eff" or "es"
+// - The name of a file in the user dictionary directory (see UserDictDir)
 // - A file path for external dictionaries.
 //
 //nolint:ireturn // Dictionary interface is the intended public API for polymorphism
 func GetDictionary(source string) (Dictionary, error) {
+	return GetDictionaryFormat(source, FormatAuto)
+}
+
+// GetDictionaryFormat is like GetDictionary, but reads file dictionaries in the given format.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func GetDictionaryFormat(source string, format Format) (Dictionary, error) {
 	source = strings.TrimSpace(source)
 	if source == "" {
 		return nil, errors.New("dictionary source cannot be empty")
@@ -85,6 +235,11 @@ func GetDictionary(source string) (Dictionary, error) {
 		return dict, nil
 	}
 
+	// Then named user dictionaries.
+	if user, err := findUser(source); err == nil {
+		return user.Load(format)
+	}
+
 	// Try as file path.
-	return NewFromFile(source)
+	return NewFromFileFormat(source, format)
 }
@@ -0,0 +1,114 @@
+package dictionary
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UserDict represents a named dictionary file in the user config directory.
+type UserDict struct {
+	Name string
+	Path string
+}
+
+// UserDictDir returns the directory holding named user dictionaries,
+// e.g. $XDG_CONFIG_HOME/pwgen/dicts on Linux.
+func UserDictDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating user config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "pwgen", "dicts"), nil
+}
+
+// ListUser returns the dictionary files in the user dictionary directory, sorted by name.
+// Each file is named after its base name without extension ("nouns.txt" becomes "nouns").
+// Hidden files and files that would shadow a built-in dictionary are skipped.
+// A missing directory yields an empty list.
+func ListUser() ([]UserDict, error) {
+	dir, err := UserDictDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading user dictionary directory: %w", err)
+	}
+
+	dicts := make([]UserDict, 0, len(entries))
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		name := strings.ToLower(strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())))
+		if builtins.Has(name) {
+			continue
+		}
+
+		dicts = append(dicts, UserDict{
+			Name: name,
+			Path: filepath.Join(dir, entry.Name()),
+		})
+	}
+
+	sort.Slice(dicts, func(i, j int) bool {
+		return dicts[i].Name < dicts[j].Name
+	})
+
+	return dicts, nil
+}
+
+// GetUser returns a user dictionary by name.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func GetUser(name string, format Format) (Dictionary, error) {
+	user, err := findUser(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.Load(format)
+}
+
+// findUser looks up a user dictionary file by name.
+func findUser(name string) (UserDict, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	dicts, err := ListUser()
+	if err != nil {
+		return UserDict{}, err
+	}
+
+	for _, dict := range dicts {
+		if dict.Name == name {
+			return dict, nil
+		}
+	}
+
+	return UserDict{}, fmt.Errorf("unknown user dictionary: %q", name)
+}
+
+// Load reads the dictionary file in the given format.
+//
+//nolint:ireturn // Dictionary interface is the intended public API for polymorphism
+func (u UserDict) Load(format Format) (Dictionary, error) {
+	words, _, err := readWordsFile(u.Path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFromWords(u.Name, words), nil
+}
@@ -0,0 +1,171 @@
+package dictionary
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ValidationReport describes the quality of a word list.
+type ValidationReport struct {
+	Name               string           `json:"name"`
+	Words              int              `json:"words"`
+	DistinctWords      int              `json:"distinctWords"`
+	EntropyBits        float64          `json:"entropyBits"`
+	Duplicates         []string         `json:"duplicates,omitempty"`
+	CaseCollisions     [][]string       `json:"caseCollisions,omitempty"`
+	EmptyWords         int              `json:"emptyWords"`
+	WhitespaceWords    []string         `json:"whitespaceWords,omitempty"`
+	PrefixFree         bool             `json:"prefixFree"`
+	PrefixConflicts    []PrefixConflict `json:"prefixConflicts,omitempty"`
+	UniquePrefixLength int              `json:"uniquePrefixLength"`
+	Valid              bool             `json:"valid"`
+}
+
+// PrefixConflict records a word that is a prefix of another word.
+type PrefixConflict struct {
+	Prefix string `json:"prefix"`
+	Word   string `json:"word"`
+}
+
+// Validate checks a word list for duplicates, case-insensitive collisions,
+// empty or whitespace-containing words and the unique-prefix property.
+// Entropy is computed on the number of distinct words after case folding,
+// since casing is applied after a word is picked.
+func Validate(name string, words []string) ValidationReport {
+	report := ValidationReport{
+		Name:  name,
+		Words: len(words),
+	}
+
+	exact := make(map[string]int, len(words))
+	folded := make(map[string][]string, len(words))
+	order := make([]string, 0, len(words))
+
+	for _, word := range words {
+		if word == "" {
+			report.EmptyWords++
+
+			continue
+		}
+
+		if strings.IndexFunc(word, unicode.IsSpace) >= 0 {
+			report.WhitespaceWords = append(report.WhitespaceWords, word)
+		}
+
+		exact[word]++
+		if exact[word] == 2 { //nolint:mnd // report each duplicate once, on its second occurrence
+			report.Duplicates = append(report.Duplicates, word)
+		}
+
+		key := foldWord(word)
+		if _, seen := folded[key]; !seen {
+			order = append(order, key)
+		}
+
+		if !slices.Contains(folded[key], word) {
+			folded[key] = append(folded[key], word)
+		}
+	}
+
+	for _, key := range order {
+		if len(folded[key]) > 1 {
+			report.CaseCollisions = append(report.CaseCollisions, folded[key])
+		}
+	}
+
+	report.DistinctWords = len(order)
+	if report.DistinctWords > 0 {
+		report.EntropyBits = math.Log2(float64(report.DistinctWords))
+	}
+
+	report.PrefixConflicts = prefixConflicts(order)
+	report.PrefixFree = len(report.PrefixConflicts) == 0
+	report.UniquePrefixLength = uniquePrefixLength(order)
+
+	report.Valid = report.DistinctWords > 0 &&
+		len(report.Duplicates) == 0 &&
+		len(report.CaseCollisions) == 0 &&
+		report.EmptyWords == 0 &&
+		len(report.WhitespaceWords) == 0
+
+	return report
+}
+
+// ValidateSource validates a dictionary by name (built-in or user) or a word list file.
+// Files are checked as written, before NewFromWords drops duplicates, and their blank lines
+// count as empty words.
+func ValidateSource(source string, format Format) (ValidationReport, error) {
+	if dict, err := GetBuiltin(source); err == nil {
+		return Validate(dict.Name(), dict.Words()), nil
+	}
+
+	name, path := source, source
+
+	if user, err := findUser(source); err == nil {
+		name, path = user.Name, user.Path
+	}
+
+	words, blank, err := readWordsFile(path, format)
+	if err != nil {
+		return ValidationReport{}, err
+	}
+
+	return Validate(name, append(words, make([]string, blank)...)), nil
+}
+
+// foldWord returns the key under which words collide once casing is applied.
+func foldWord(word string) string {
+	return cases.Fold().String(norm.NFC.String(word))
+}
+
+// prefixConflicts finds words that are a prefix of another word.
+// Such lists cannot be split unambiguously when words are joined without a separator.
+func prefixConflicts(words []string) []PrefixConflict {
+	sorted := slices.Clone(words)
+	slices.Sort(sorted)
+
+	var conflicts []PrefixConflict
+
+	// If a word prefixes any later word in sorted order, it also prefixes the next one.
+	for i := 0; i+1 < len(sorted); i++ {
+		if strings.HasPrefix(sorted[i+1], sorted[i]) {
+			conflicts = append(conflicts, PrefixConflict{Prefix: sorted[i], Word: sorted[i+1]})
+		}
+	}
+
+	return conflicts
+}
+
+// uniquePrefixLength returns the smallest n for which the first n characters
+// identify every word, or 0 if no such n exists.
+func uniquePrefixLength(words []string) int {
+	longest := 0
+
+	for _, word := range words {
+		longest = max(longest, len([]rune(word)))
+	}
+
+	for length := 1; length <= longest; length++ {
+		seen := make(map[string]bool, len(words))
+
+		for _, word := range words {
+			prefix := string([]rune(word)[:min(length, len([]rune(word)))])
+			if seen[prefix] {
+				break
+			}
+
+			seen[prefix] = true
+		}
+
+		if len(seen) == len(words) {
+			return length
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,220 @@
+package dictionary
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		words []string
+		want  ValidationReport
+	}{
+		{
+			name:  "clean list",
+			words: []string{"apple", "banana", "cherry", "date"},
+			want: ValidationReport{
+				Words:              4,
+				DistinctWords:      4,
+				EntropyBits:        2,
+				PrefixFree:         true,
+				UniquePrefixLength: 1,
+				Valid:              true,
+			},
+		},
+		{
+			name:  "duplicates are reported once and counted once",
+			words: []string{"apple", "banana", "apple", "apple"},
+			want: ValidationReport{
+				Words:              4,
+				DistinctWords:      2,
+				EntropyBits:        1,
+				Duplicates:         []string{"apple"},
+				PrefixFree:         true,
+				UniquePrefixLength: 1,
+			},
+		},
+		{
+			name:  "case collisions",
+			words: []string{"Apple", "apple", "banana", "APPLE"},
+			want: ValidationReport{
+				Words:              4,
+				DistinctWords:      2,
+				EntropyBits:        1,
+				CaseCollisions:     [][]string{{"Apple", "apple", "APPLE"}},
+				PrefixFree:         true,
+				UniquePrefixLength: 1,
+			},
+		},
+		{
+			name:  "case collisions after normalization",
+			words: []string{"café", "CAFÉ", "tea"},
+			want: ValidationReport{
+				Words:              3,
+				DistinctWords:      2,
+				EntropyBits:        1,
+				CaseCollisions:     [][]string{{"café", "CAFÉ"}},
+				PrefixFree:         true,
+				UniquePrefixLength: 1,
+			},
+		},
+		{
+			name:  "empty and whitespace words",
+			words: []string{"apple", "", "ice cream", "banana"},
+			want: ValidationReport{
+				Words:              4,
+				DistinctWords:      3,
+				EntropyBits:        math.Log2(3),
+				EmptyWords:         1,
+				WhitespaceWords:    []string{"ice cream"},
+				PrefixFree:         true,
+				UniquePrefixLength: 1,
+			},
+		},
+		{
+			name:  "prefix conflicts",
+			words: []string{"car", "cart", "carton", "dog"},
+			want: ValidationReport{
+				Words:         4,
+				DistinctWords: 4,
+				EntropyBits:   2,
+				PrefixConflicts: []PrefixConflict{
+					{Prefix: "car", Word: "cart"},
+					{Prefix: "cart", Word: "carton"},
+				},
+				UniquePrefixLength: 5,
+				Valid:              true,
+			},
+		},
+		{
+			name:  "unique prefix length",
+			words: []string{"abacus", "abdomen", "acid", "bacon"},
+			want: ValidationReport{
+				Words:              4,
+				DistinctWords:      4,
+				EntropyBits:        2,
+				PrefixFree:         true,
+				UniquePrefixLength: 3,
+				Valid:              true,
+			},
+		},
+		{
+			name:  "empty list",
+			words: nil,
+			want: ValidationReport{
+				PrefixFree: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.want.Name = tt.name
+
+			got := Validate(tt.name, tt.words)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate(%q) =\n%+v\nwant\n%+v", tt.words, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSourceCountsBlankLines(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("apple\n\nbanana\n# comment\n  \ncherry\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := ValidateSource(path, FormatPlain)
+	if err != nil {
+		t.Fatalf("ValidateSource() error = %v", err)
+	}
+
+	if report.EmptyWords != 2 || report.DistinctWords != 3 || report.Valid {
+		t.Errorf("ValidateSource() = %+v, want 2 empty words, 3 distinct words and not valid", report)
+	}
+}
+
+func TestNewFromWordsCountsDistinctWords(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		words     []string
+		wantWords []string
+	}{
+		{
+			name:      "distinct words",
+			words:     []string{"apple", "banana", "cherry", "date"},
+			wantWords: []string{"apple", "banana", "cherry", "date"},
+		},
+		{
+			name:      "duplicates and case collisions keep the first word",
+			words:     []string{"apple", "Apple", "banana", "apple", "BANANA"},
+			wantWords: []string{"apple", "banana"},
+		},
+		{
+			name:      "empty words are dropped and words are normalized",
+			words:     []string{"", "café", "café", "tea"},
+			wantWords: []string{"café", "tea"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dict := NewFromWords(tt.name, tt.words)
+
+			if got := dict.Words(); !reflect.DeepEqual(got, tt.wantWords) {
+				t.Errorf("Words() = %q, want %q", got, tt.wantWords)
+			}
+
+			if got, want := dict.EntropyBits(), math.Log2(float64(len(tt.wantWords))); got != want {
+				t.Errorf("EntropyBits() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateBuiltins(t *testing.T) {
+	t.Parallel()
+
+	for _, info := range ListBuiltin() {
+		t.Run(info.Name, func(t *testing.T) {
+			t.Parallel()
+
+			dict := info.Factory()
+
+			report := Validate(dict.Name(), dict.Words())
+			if !report.Valid {
+				t.Errorf("built-in dictionary is not valid: %+v", report)
+			}
+
+			if report.DistinctWords != info.WordCount {
+				t.Errorf("DistinctWords = %d, want %d", report.DistinctWords, info.WordCount)
+			}
+		})
+	}
+
+	report := Validate("eff-short2", EFFShort2().Words())
+	if report.UniquePrefixLength != 3 {
+		t.Errorf("eff-short2 UniquePrefixLength = %d, want 3", report.UniquePrefixLength)
+	}
+
+	for _, word := range EFFShort().Words() {
+		if len(word) > 5 {
+			t.Errorf("eff-short word %q is longer than 5 letters", word)
+		}
+	}
+}
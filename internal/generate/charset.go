@@ -0,0 +1,235 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/idelchi/pwgen/internal/random"
+)
+
+// Character sets of the built-in character classes.
+const (
+	// AlphanumericCharset is used by "C" tokens and --chars.
+	AlphanumericCharset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	// HexCharset is used by "X" tokens and --hex.
+	HexCharset = "0123456789abcdef"
+	// Base32Charset is the RFC 4648 base32 alphabet used by "B32" tokens.
+	Base32Charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+)
+
+const (
+	// maxCharsetSize is the largest accepted custom character class.
+	maxCharsetSize = 1024
+	// minRangeLength is the shortest run of consecutive characters rendered as a range ("a-c").
+	minRangeLength = 3
+)
+
+// CharsetToken generates random characters from a character class,
+// as in "C{16}", "X{32}", "B32{26}" or "[a-z0-9]{12}".
+type CharsetToken struct {
+	Charset string // Distinct characters to choose from
+	Count   int    // Number of characters to generate
+}
+
+// Generate produces Count characters, each drawn uniformly from the charset.
+func (c *CharsetToken) Generate(source io.Reader) (string, error) {
+	if c.Count <= 0 {
+		return "", errors.New("character count must be positive")
+	}
+
+	chars := []rune(c.Charset)
+	if len(chars) == 0 {
+		return "", errors.New("charset cannot be empty")
+	}
+
+	var result strings.Builder
+	result.Grow(c.Count)
+
+	for range c.Count {
+		idx, err := random.Int(source, len(chars))
+		if err != nil {
+			return "", fmt.Errorf("generating random character: %w", err)
+		}
+
+		result.WriteRune(chars[idx])
+	}
+
+	return result.String(), nil
+}
+
+// EntropyBits returns the entropy contributed by this character token.
+func (c *CharsetToken) EntropyBits() float64 {
+	size := len([]rune(c.Charset))
+	if c.Count <= 0 || size == 0 {
+		return 0
+	}
+
+	// log2(charset_size^count) = count * log2(charset_size)
+	return float64(c.Count) * math.Log2(float64(size))
+}
+
+// Type returns a description of this token type.
+func (c *CharsetToken) Type() string {
+	var name string
+
+	switch c.Charset {
+	case AlphanumericCharset:
+		name = "alnum"
+	case HexCharset:
+		name = "hex"
+	case Base32Charset:
+		name = "base32"
+	default:
+		name = "chars" + renderCharClass(c.Charset)
+	}
+
+	return fmt.Sprintf("%s(%d)", name, c.Count)
+}
+
+// DSL returns the character token in pattern DSL syntax, e.g. "X{32}" or "[a-z0-9]{12}".
+func (c *CharsetToken) DSL() string {
+	var element string
+
+	switch c.Charset {
+	case AlphanumericCharset:
+		element = "C"
+	case HexCharset:
+		element = "X"
+	case Base32Charset:
+		element = "B32"
+	default:
+		element = renderCharClass(c.Charset)
+	}
+
+	if c.Count == 1 {
+		return element
+	}
+
+	return fmt.Sprintf("%s{%d}", element, c.Count)
+}
+
+// parseCharClass returns the distinct characters of a bracketed class body such as "a-z0-9_".
+// A backslash escapes the next character, so "\-" and "\]" stand for themselves.
+// Negated classes are not supported, so an unescaped leading "^" is an error rather than a literal.
+func parseCharClass(body string) (string, error) {
+	runes := []rune(body)
+
+	if len(runes) > 0 && runes[0] == '^' {
+		return "", errors.New(`negated character classes are not supported; write \^ for a literal "^"`)
+	}
+	seen := make(map[rune]bool)
+
+	for i := 0; i < len(runes); i++ {
+		low, err := classRune(runes, &i)
+		if err != nil {
+			return "", err
+		}
+
+		high := low
+
+		if i+2 < len(runes) && runes[i+1] == '-' {
+			i += 2
+
+			high, err = classRune(runes, &i)
+			if err != nil {
+				return "", err
+			}
+
+			if high < low {
+				return "", fmt.Errorf("invalid range %c-%c in character class", low, high)
+			}
+		}
+
+		if int(high-low) >= maxCharsetSize {
+			return "", fmt.Errorf("character class exceeds %d characters", maxCharsetSize)
+		}
+
+		for r := low; r <= high; r++ {
+			seen[r] = true
+		}
+
+		if len(seen) > maxCharsetSize {
+			return "", fmt.Errorf("character class exceeds %d characters", maxCharsetSize)
+		}
+	}
+
+	if len(seen) == 0 {
+		return "", errors.New("character class is empty")
+	}
+
+	chars := make([]rune, 0, len(seen))
+
+	for r := range seen {
+		chars = append(chars, r)
+	}
+
+	slices.Sort(chars)
+
+	return string(chars), nil
+}
+
+// classRune returns the (possibly escaped) character at runes[*i], advancing *i past the escape.
+func classRune(runes []rune, i *int) (rune, error) {
+	if runes[*i] == '\\' {
+		if *i+1 == len(runes) {
+			return 0, errors.New("character class ends with an escape")
+		}
+
+		*i++
+	}
+
+	if r := runes[*i]; !unicode.IsPrint(r) {
+		return 0, fmt.Errorf("character %U is not printable", r)
+	}
+
+	return runes[*i], nil
+}
+
+// renderCharClass renders a charset as a bracketed class, collapsing runs of consecutive
+// characters into ranges, so that parseCharClass reads it back unchanged.
+func renderCharClass(charset string) string {
+	chars := []rune(charset)
+	slices.Sort(chars)
+	chars = slices.Compact(chars)
+
+	var result strings.Builder
+
+	result.WriteString("[")
+
+	for i := 0; i < len(chars); {
+		end := i
+
+		for end+1 < len(chars) && chars[end+1] == chars[end]+1 {
+			end++
+		}
+
+		if end-i+1 >= minRangeLength {
+			result.WriteString(escapeClassRune(chars[i], i == 0) + "-" + escapeClassRune(chars[end], false))
+		} else {
+			for j, r := range chars[i : end+1] {
+				result.WriteString(escapeClassRune(r, i+j == 0))
+			}
+		}
+
+		i = end + 1
+	}
+
+	result.WriteString("]")
+
+	return result.String()
+}
+
+// escapeClassRune escapes characters with a special meaning inside a character class,
+// and "^" when it comes first.
+func escapeClassRune(r rune, first bool) string {
+	if strings.ContainsRune(`\]-[`, r) || (first && r == '^') {
+		return `\` + string(r)
+	}
+
+	return string(r)
+}
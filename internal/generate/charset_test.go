@@ -0,0 +1,61 @@
+package generate
+
+import "testing"
+
+func TestParseCharClass(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		// want is the parsed charset, empty if an error is expected.
+		want string
+	}{
+		{name: "ranges", body: "a-c0-2", want: "012abc"},
+		{name: "escapes", body: `\-\]\\`, want: `-\]`},
+		{name: "escaped caret", body: `\^a`, want: "^a"},
+		{name: "caret after the first character", body: "a^", want: "^a"},
+		{name: "negated class", body: "^a-z"},
+		{name: "caret alone", body: "^"},
+		{name: "reversed range", body: "z-a"},
+		{name: "trailing escape", body: `a\`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseCharClass(test.body)
+
+			switch {
+			case test.want == "" && err == nil:
+				t.Errorf("parseCharClass(%q) = %q, want an error", test.body, got)
+			case test.want == "":
+			case err != nil:
+				t.Errorf("parseCharClass(%q) error = %v", test.body, err)
+			case got != test.want:
+				t.Errorf("parseCharClass(%q) = %q, want %q", test.body, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenderCharClassRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// The charsets are sorted, as parseCharClass returns them.
+	for _, charset := range []string{"^_`", "^a", `-\]^`, "abcdef", "^"} {
+		rendered := renderCharClass(charset)
+
+		got, err := parseCharClass(rendered[1 : len(rendered)-1])
+		if err != nil {
+			t.Errorf("parseCharClass(%q) error = %v", rendered, err)
+
+			continue
+		}
+
+		if got != charset {
+			t.Errorf("parseCharClass(%q) = %q, want %q", rendered, got, charset)
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package generate
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+// characterClass is a set of the character classes a policy can require.
+type characterClass uint8
+
+const (
+	classUpper characterClass = 1 << iota
+	classLower
+	classDigit
+	classSymbol
+
+	// classSets is the number of distinct sets of character classes.
+	classSets = 1 << 4
+)
+
+// classOf returns the class of r as Violations counts it. Letters without case belong to no class.
+func classOf(r rune) characterClass {
+	switch {
+	case unicode.IsUpper(r):
+		return classUpper
+	case unicode.IsLower(r):
+		return classLower
+	case unicode.IsDigit(r):
+		return classDigit
+	case !unicode.IsLetter(r):
+		return classSymbol
+	default:
+		return 0
+	}
+}
+
+// compositions describes the outputs of a token that the policy's character rules allow,
+// indexed by length in characters and then by the set of character classes they contain,
+// with their probability and average surprisal as in lengthClass.
+// Outputs with a character the policy does not allow are left out, so the probabilities
+// add up to the fraction of outputs kept.
+type compositions [][classSets]lengthClass
+
+// add adds outputs with the given probability and average surprisal. The surprisal is kept
+// weighted by probability until normalize.
+func (c *compositions) add(length int, classes characterClass, probability, bits float64) {
+	if probability == 0 {
+		return
+	}
+
+	for len(*c) <= length {
+		*c = append(*c, [classSets]lengthClass{})
+	}
+
+	class := &(*c)[length][classes]
+	class.probability += probability
+	class.bits += probability * bits
+}
+
+// normalize turns the probability-weighted surprisals into averages.
+func (c compositions) normalize() compositions {
+	for length := range c {
+		for classes := range c[length] {
+			if class := &c[length][classes]; class.probability > 0 {
+				class.bits /= class.probability
+			}
+		}
+	}
+
+	return c
+}
+
+// combineCompositions combines the compositions of two independent parts:
+// lengths and surprisals add up, and the classes of both are present.
+func combineCompositions(first, second compositions) compositions {
+	var combined compositions
+
+	for i := range first {
+		for a, x := range first[i] {
+			if x.probability == 0 {
+				continue
+			}
+
+			for j := range second {
+				for b, y := range second[j] {
+					combined.add(i+j, characterClass(a|b), x.probability*y.probability, x.bits+y.bits)
+				}
+			}
+		}
+	}
+
+	return combined.normalize()
+}
+
+// required returns the character classes the policy requires.
+func (p *Policy) required() characterClass {
+	var classes characterClass
+
+	for _, rule := range []struct {
+		required bool
+		class    characterClass
+	}{
+		{p.RequireUpper, classUpper},
+		{p.RequireLower, classLower},
+		{p.RequireDigit, classDigit},
+		{p.RequireSymbol, classSymbol},
+	} {
+		if rule.required {
+			classes |= rule.class
+		}
+	}
+
+	return classes
+}
+
+// allowsRune reports whether the policy allows r: it is not forbidden and, for a symbol,
+// one of the allowed symbols.
+func (p *Policy) allowsRune(r rune) bool {
+	if classOf(r) == classSymbol && p.AllowedSymbols != "" && !strings.ContainsRune(p.AllowedSymbols, r) {
+		return false
+	}
+
+	return p.permitted(r)
+}
+
+// compositions returns the compositions of the pattern's passphrases, crediting the choices
+// the same way as tokensLengths, and false if they are not known: for rules on repeated characters,
+// for tokens whose characters are not tracked, such as shuffles and mnemonics,
+// and for patterns with too many variants.
+func (p *Policy) compositions(pattern *Pattern) (compositions, bool) {
+	if p.MaxRepeat > 0 {
+		return nil, false
+	}
+
+	resolved, ok := variants(pattern.Tokens)
+	if !ok {
+		return nil, false
+	}
+
+	extra := groupSurprisals(resolved)
+	cache := map[string]compositions{}
+
+	var total compositions
+
+	for i, v := range resolved {
+		outputs := compositions{{{probability: 1}}}
+		capitalized := false
+
+		for _, e := range v.elements {
+			inner, ok := p.elementCompositions(e, &capitalized, cache)
+			if !ok {
+				return nil, false
+			}
+
+			outputs = combineCompositions(outputs, inner)
+		}
+
+		for length := range outputs {
+			for classes, class := range outputs[length] {
+				total.add(length, characterClass(classes), v.probability*class.probability, class.bits+extra[i])
+			}
+		}
+	}
+
+	return total.normalize(), true
+}
+
+// accepted returns the entropy of the outputs the policy accepts, each kept output becoming
+// more likely by the same factor, together with their probability, as constrainedEntropy
+// does for lengths.
+func (p *Policy) accepted(outputs compositions) (float64, float64) {
+	required := p.required()
+	probability, surprisal := 0.0, 0.0
+
+	for length := range outputs {
+		if length < p.MinLength || (p.MaxLength > 0 && length > p.MaxLength) {
+			continue
+		}
+
+		for classes, class := range outputs[length] {
+			if characterClass(classes)&required != required {
+				continue
+			}
+
+			probability += class.probability
+			surprisal += class.probability * class.bits
+		}
+	}
+
+	if probability == 0 {
+		return 0, 0
+	}
+
+	return surprisal/probability + math.Log2(probability), probability
+}
+
+// elementCompositions returns the compositions of one element of a variant.
+// capitalized records whether a first-upper word was written before it, as Generation does.
+func (p *Policy) elementCompositions(
+	e element,
+	capitalized *bool,
+	cache map[string]compositions,
+) (compositions, bool) {
+	if e.alphabet != "" {
+		var outputs compositions
+
+		runes := []rune(e.alphabet)
+
+		for _, r := range runes {
+			if p.allowsRune(r) {
+				outputs.add(1, classOf(r), 1/float64(len(runes)), e.bits)
+			}
+		}
+
+		return outputs.normalize(), true
+	}
+
+	switch t := e.token.(type) {
+	case *WordToken:
+		casing := writtenCasing(t.Casing, capitalized)
+
+		return cachedCompositions(cache, e.key+":"+casing.String(), func() (compositions, bool) {
+			return p.wordCompositions(t, casing)
+		})
+	case *SyllableToken:
+		casing := writtenCasing(t.Casing, capitalized)
+
+		return cachedCompositions(cache, e.key+":"+casing.String(), func() (compositions, bool) {
+			return p.syllableCompositions(t, casing)
+		})
+	default:
+		return nil, false
+	}
+}
+
+// writtenCasing returns the casing a word is written in, resolving first-upper casing as wordCasing does.
+func writtenCasing(style CaseStyle, capitalized *bool) CaseStyle {
+	if style != CaseFirstUpper {
+		return style
+	}
+
+	if *capitalized {
+		return CaseLower
+	}
+
+	*capitalized = true
+
+	return CaseTitle
+}
+
+// cachedCompositions returns the compositions stored under key, computing them on first use.
+func cachedCompositions(
+	cache map[string]compositions,
+	key string,
+	compute func() (compositions, bool),
+) (compositions, bool) {
+	if outputs, ok := cache[key]; ok {
+		return outputs, true
+	}
+
+	outputs, ok := compute()
+	if ok {
+		cache[key] = outputs
+	}
+
+	return outputs, ok
+}
+
+// wordCompositions returns the compositions of the token's words written in casing.
+func (p *Policy) wordCompositions(token *WordToken, casing CaseStyle) (compositions, bool) {
+	words := token.Dict.Words()
+	if len(words) == 0 {
+		return nil, false
+	}
+
+	var outputs compositions
+
+	share := 1 / float64(len(words))
+
+	for _, word := range words {
+		if !p.addCased(&outputs, word, casing, token.Dict.Language(), share, token.Dict.EntropyBits()) {
+			return nil, false
+		}
+	}
+
+	return outputs.normalize(), true
+}
+
+// syllableCompositions returns the compositions of the token's syllables written in casing,
+// and false if the policy forbids some of their letters.
+func (p *Policy) syllableCompositions(token *SyllableToken, casing CaseStyle) (compositions, bool) {
+	if token.Count <= 0 {
+		return nil, false
+	}
+
+	for r := range casedRunes(syllables(), language.Und) {
+		if !p.allowsRune(r) {
+			return nil, false
+		}
+	}
+
+	var outputs compositions
+
+	bits := float64(token.Count) * math.Log2(float64(len(syllables())))
+
+	// Syllables are lowercase ASCII letters, so the classes of each casing only depend on the length.
+	for length, probability := range token.lengthDistribution() {
+		if probability > 0 && !p.addCased(&outputs, strings.Repeat("a", length), casing, language.Und, probability, bits) {
+			return nil, false
+		}
+	}
+
+	return outputs.normalize(), true
+}
+
+// addCased adds the outputs of word written in casing, together having the given probability
+// and bits of entropy before casing. It returns false if the compositions cannot track them.
+func (p *Policy) addCased(
+	outputs *compositions,
+	word string,
+	casing CaseStyle,
+	lang language.Tag,
+	probability, bits float64,
+) bool {
+	switch casing {
+	case CaseMixed:
+		return p.addMixedCase(outputs, word, lang, probability, bits)
+	case CaseRandomTitle:
+		for _, style := range []CaseStyle{CaseTitle, CaseLower} {
+			p.addText(outputs, casedWord(word, style, lang), probability/2, bits+casing.EntropyBits(word))
+		}
+
+		return true
+	default:
+		p.addText(outputs, casedWord(word, casing, lang), probability, bits)
+
+		return true
+	}
+}
+
+// casedWord returns word written in a casing style that draws no randomness.
+func casedWord(word string, style CaseStyle, lang language.Tag) string {
+	cased, err := ApplyCasing(word, style, lang, nil)
+	if err != nil {
+		return word
+	}
+
+	return cased
+}
+
+// addText adds a fixed text with the given probability and surprisal, unless the policy forbids
+// one of its characters.
+func (p *Policy) addText(outputs *compositions, text string, probability, bits float64) {
+	var classes characterClass
+
+	for _, r := range text {
+		if !p.allowsRune(r) {
+			return
+		}
+
+		classes |= classOf(r)
+	}
+
+	outputs.add(utf8.RuneCountInString(text), classes, probability, bits)
+}
+
+// caseGroup is the case patterns of a word's cased letters that give one set of character classes,
+// with their total probability and total -p*log2(p).
+type caseGroup struct {
+	classes                characterClass
+	probability, surprisal float64
+}
+
+// addMixedCase adds the outputs of applyMixedCase for word, grouped into those with only
+// lowercase, only uppercase and both cases of its cased letters. It returns false if
+// the case of a letter decides whether the policy forbids it.
+func (p *Policy) addMixedCase(
+	outputs *compositions,
+	word string,
+	lang language.Tag,
+	probability, bits float64,
+) bool {
+	toUpper, toLower := unicode.ToUpper, unicode.ToLower
+	if special := letterCase(lang); special != nil {
+		toUpper, toLower = special.ToUpper, special.ToLower
+	}
+
+	var fixed characterClass
+
+	letters, cased := 0, 0
+	firstCased := false
+
+	for _, r := range word {
+		isCased := unicode.IsLetter(r) && unicode.ToUpper(r) != unicode.ToLower(r)
+
+		if unicode.IsLetter(r) {
+			if letters == 0 {
+				firstCased = isCased
+			}
+
+			letters++
+		}
+
+		switch {
+		case isCased:
+			if !p.allowsRune(toUpper(r)) || !p.allowsRune(toLower(r)) {
+				return false
+			}
+
+			cased++
+		case !p.allowsRune(r):
+			// Every casing of the word is discarded.
+			return true
+		default:
+			fixed |= classOf(r)
+		}
+	}
+
+	length := utf8.RuneCountInString(word)
+
+	if cased == 0 {
+		outputs.add(length, fixed, probability, bits)
+
+		return true
+	}
+
+	// As in mixedCaseBits, each case pattern of the cased letters has probability 2^-cased,
+	// except that the all-lowercase pattern gives forced to the pattern with only the first letter uppercase.
+	uniform := math.Exp2(-float64(cased))
+	forced := 0.0
+
+	if firstCased {
+		forced = math.Exp2(-float64(letters))
+	}
+
+	allLower, firstOnly := uniform-forced, uniform+forced
+
+	groups := []caseGroup{
+		{classLower, allLower, surprisal(allLower)},
+		{classUpper, firstOnly, surprisal(firstOnly)},
+	}
+
+	if cased > 1 {
+		// The first-only pattern mixes both cases; the all-uppercase one keeps its uniform probability.
+		patterns := math.Exp2(float64(cased))
+		groups = []caseGroup{
+			{classLower, allLower, surprisal(allLower)},
+			{classUpper, uniform, surprisal(uniform)},
+			{classUpper | classLower, (patterns-3)*uniform + firstOnly, (patterns-3)*surprisal(uniform) + surprisal(firstOnly)},
+		}
+	}
+
+	for _, group := range groups {
+		if group.probability > 0 {
+			outputs.add(length, fixed|group.classes, probability*group.probability, bits+group.surprisal/group.probability)
+		}
+	}
+
+	return true
+}
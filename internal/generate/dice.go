@@ -0,0 +1,148 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+	"github.com/idelchi/pwgen/internal/random"
+)
+
+// BuildFromRolls creates a pattern of words picked by physical dice rolls, one roll per word,
+// joined by sep and cased as given. The dictionary must be a diceware list (see dictionary.DiceDigits).
+//
+// The words are fixed, but the pattern's entropy is that of words picked at random,
+// as fair dice pick them: the same as BuildFromOptions with as many words.
+// Random casing styles still draw from the pattern's source.
+func (pb *PatternBuilder) BuildFromRolls(rolls []string, casing, sep string) (*Pattern, error) {
+	if len(rolls) == 0 {
+		return nil, errors.New("no dice rolls given")
+	}
+
+	words, err := dictionary.DiceWords(pb.defaultDict, rolls)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := pb.BuildFromOptions(len(rolls), 0, 0, casing, sep, PlacementEnd.String(), false, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	next := 0
+
+	walkTokens(pattern.Tokens, func(token Token) {
+		if word, ok := token.(*WordToken); ok {
+			word.Dict = &rolledDict{Dictionary: word.Dict, word: words[next]}
+			next++
+		}
+	})
+
+	return pattern, nil
+}
+
+// GenerateFromRolls creates a passphrase from physical dice rolls, as built by BuildFromRolls.
+func (g *Generator) GenerateFromRolls(rolls []string, casing, sep string) (Result, error) {
+	pattern, err := g.patternBuilder.BuildFromRolls(rolls, casing, sep)
+	if err != nil {
+		return Result{}, fmt.Errorf("building pattern: %w", err)
+	}
+
+	passphrase, err := pattern.Generate()
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := NewResult(passphrase, pattern.EntropyBits(), pattern)
+
+	for _, roll := range rolls {
+		result.Rolls = append(result.Rolls, strings.TrimSpace(roll))
+	}
+
+	return result, nil
+}
+
+// rolledDict is a dictionary whose random word is always the one picked by a dice roll.
+// Everything else, including its entropy, is that of the dictionary rolled on.
+type rolledDict struct {
+	dictionary.Dictionary
+
+	word string
+}
+
+// RandomWord returns the rolled word.
+func (d *rolledDict) RandomWord(_ io.Reader) (string, error) {
+	return d.word, nil
+}
+
+// rollLog records the dice rolls of the words picked for a passphrase.
+type rollLog struct {
+	rolls []string
+}
+
+// reset forgets the rolls of a discarded passphrase. It does nothing on a nil log.
+func (l *rollLog) reset() {
+	if l != nil {
+		l.rolls = nil
+	}
+}
+
+// take returns the recorded rolls and forgets them. It returns nil on a nil log.
+func (l *rollLog) take() []string {
+	if l == nil {
+		return nil
+	}
+
+	rolls := l.rolls
+	l.rolls = nil
+
+	return rolls
+}
+
+// recordingDict is a dictionary that records the dice roll of every word it picks.
+type recordingDict struct {
+	dictionary.Dictionary
+
+	words  []string
+	digits int
+	log    *rollLog
+}
+
+// RandomWord returns a uniformly random word, drawn exactly as the wrapped dictionary would,
+// and records its roll.
+func (d *recordingDict) RandomWord(source io.Reader) (string, error) {
+	index, err := random.Int(source, len(d.words))
+	if err != nil {
+		return "", fmt.Errorf("generating random number: %w", err)
+	}
+
+	d.log.rolls = append(d.log.rolls, dictionary.DiceRoll(index, d.digits))
+
+	return d.words[index], nil
+}
+
+// recordRolls makes the word tokens of the pattern record the dice rolls of their words in log.
+// Every dictionary of the pattern must be a diceware list.
+func recordRolls(pattern *Pattern, log *rollLog) error {
+	var err error
+
+	walkTokens(pattern.Tokens, func(token Token) {
+		word, ok := token.(*WordToken)
+		if !ok || err != nil {
+			return
+		}
+
+		digits, digitsErr := dictionary.DiceDigits(word.Dict)
+		if digitsErr != nil {
+			err = fmt.Errorf("showing dice rolls: %w", digitsErr)
+
+			return
+		}
+
+		word.Dict = &recordingDict{Dictionary: word.Dict, words: word.Dict.Words(), digits: digits, log: log}
+	})
+
+	return err
+}
@@ -0,0 +1,888 @@
+package generate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+)
+
+const (
+	// maxQuantifier is the largest accepted repeat or count, to keep patterns from exploding.
+	maxQuantifier = 1000
+	// maxExpandedSize is the largest accepted number of elements of a pattern with its repeats expanded,
+	// so that nested quantifiers such as "((D{1000}){1000}){1000}" are rejected too.
+	maxExpandedSize = 10000
+)
+
+// wordRangePattern matches the body of a word length range such as "<3-6>".
+var wordRangePattern = regexp.MustCompile(`^(\d*)-(\d*)$`)
+
+// PatternError reports an invalid pattern together with the column (1-based, in characters)
+// where the problem was found.
+type PatternError struct {
+	Column int
+	Err    error
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("column %d: %v", e.Column, e.Err)
+}
+
+func (e *PatternError) Unwrap() error {
+	return e.Err
+}
+
+// patternErrorf creates a PatternError for the given column.
+func patternErrorf(column int, format string, args ...any) error {
+	return &PatternError{Column: column, Err: fmt.Errorf(format, args...)}
+}
+
+// itemKind identifies the lexical items of the pattern DSL.
+type itemKind int
+
+const (
+	itemEOF      itemKind = iota
+	itemIdent             // element name: W, DDD, S, SEP, B32, BIP39
+	itemString            // quoted literal: "corp-"
+	itemClass             // character class: [a-z0-9]
+	itemName              // dictionary name: @nouns
+	itemRange             // word length range: <3-6>
+	itemStyle             // casing style: :title
+	itemCount             // quantifier: {3}
+	itemLParen            // (
+	itemRParen            // )
+	itemPipe              // |
+	itemOptional          // ?
+	itemComma             // ,
+	itemEquals            // =
+)
+
+func (k itemKind) String() string {
+	switch k {
+	case itemEOF:
+		return "end of pattern"
+	case itemIdent:
+		return "element"
+	case itemString:
+		return "literal"
+	case itemClass:
+		return "'['"
+	case itemName:
+		return "'@'"
+	case itemRange:
+		return "'<'"
+	case itemStyle:
+		return "':'"
+	case itemCount:
+		return "'{'"
+	case itemLParen:
+		return "'('"
+	case itemRParen:
+		return "')'"
+	case itemPipe:
+		return "'|'"
+	case itemOptional:
+		return "'?'"
+	case itemComma:
+		return "','"
+	case itemEquals:
+		return "'='"
+	default:
+		return "unknown"
+	}
+}
+
+// item is a lexical item of the pattern DSL.
+type item struct {
+	kind  itemKind
+	value string
+	// column is the 1-based position of the item's first character.
+	column int
+	// spaced reports whether whitespace precedes the item.
+	spaced bool
+}
+
+// lexPattern splits a DSL string into items.
+//
+// Grammar (whitespace separates elements and is otherwise ignored):
+//
+//	pattern     = sequence
+//	sequence    = { alternation }
+//	alternation = postfix { "|" postfix }
+//	postfix     = atom { "{" n "}" | "?" }
+//	atom        = "(" sequence ")" | literal | "SEP" [ "(" literal ")" ]
+//	            | ( "SHUFFLE" | "INSIDE" ) "(" [ literal "," ] sequence ")"
+//	            | "W" [ "@" name ] [ "<" [min] "-" [max] ">" ] [ "(" filter { "," filter } ")" ] [ ":" style ]
+//	            | "P"... [ "{" n "}" ] [ ":" style ]
+//	            | ( "D"... | "S"... | "C"... | "X"... | "B32" | "[" class "]" ) [ "{" n "}" ]
+//	            | "BIP39" [ "{" bits "}" ]
+//	filter      = ( "include" | "exclude" | "blocklist" ) "=" literal
+func lexPattern(dsl string) ([]item, error) {
+	runes := []rune(dsl)
+	items := make([]item, 0, len(runes))
+	spaced := true
+
+	for i := 0; i < len(runes); {
+		r := runes[i] //nolint:varnamelen // r is standard for rune
+		column := i + 1
+
+		if unicode.IsSpace(r) {
+			spaced = true
+			i++
+
+			continue
+		}
+
+		next := item{column: column, spaced: spaced}
+		spaced = false
+
+		switch {
+		case isLetter(r):
+			end := scanWhile(runes, i, isIdentRune)
+			next.kind, next.value = itemIdent, string(runes[i:end])
+			i = end
+		case r == '"':
+			end, err := scanString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+
+			next.kind, next.value = itemString, string(runes[i:end])
+			i = end
+		case r == '[':
+			end, err := scanClass(runes, i)
+			if err != nil {
+				return nil, err
+			}
+
+			next.kind, next.value = itemClass, string(runes[i+1:end-1])
+			i = end
+		case r == '@':
+			end := scanWhile(runes, i+1, isNameRune)
+			if end == i+1 {
+				return nil, patternErrorf(column, "expected dictionary name after '@'")
+			}
+
+			next.kind, next.value = itemName, string(runes[i+1:end])
+			i = end
+		case r == ':':
+			end := scanWhile(runes, i+1, isStyleRune)
+			if end == i+1 {
+				return nil, patternErrorf(column, "expected casing style after ':'")
+			}
+
+			next.kind, next.value = itemStyle, string(runes[i+1:end])
+			i = end
+		case r == '<', r == '{':
+			closing := map[rune]rune{'<': '>', '{': '}'}[r]
+
+			end := scanWhile(runes, i+1, func(c rune) bool { return c != closing })
+			if end == len(runes) {
+				return nil, patternErrorf(column, "missing %q for %q", closing, r)
+			}
+
+			next.kind, next.value = itemCount, string(runes[i+1:end])
+			if r == '<' {
+				next.kind = itemRange
+			}
+
+			i = end + 1
+		default:
+			kinds := map[rune]itemKind{
+				'(': itemLParen, ')': itemRParen, '|': itemPipe, '?': itemOptional, ',': itemComma, '=': itemEquals,
+			}
+
+			kind, ok := kinds[r]
+			if !ok {
+				return nil, patternErrorf(column, "unexpected character %q", r)
+			}
+
+			next.kind = kind
+			i++
+		}
+
+		items = append(items, next)
+	}
+
+	return append(items, item{kind: itemEOF, column: len(runes) + 1, spaced: spaced}), nil
+}
+
+// isLetter reports whether r may appear in an element name or casing style.
+func isLetter(r rune) bool {
+	return r < unicode.MaxASCII && unicode.IsLetter(r)
+}
+
+// isStyleRune reports whether r may appear in a casing style, as in "random-title".
+func isStyleRune(r rune) bool {
+	return isLetter(r) || r == '-'
+}
+
+// isIdentRune reports whether r may continue an element name, as in "B32".
+func isIdentRune(r rune) bool {
+	return isLetter(r) || ('0' <= r && r <= '9')
+}
+
+// isNameRune reports whether r may appear in a dictionary name or path.
+func isNameRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune(`"<>:{}()|?,`, r)
+}
+
+// scanWhile returns the index of the first rune at or after start that does not satisfy accept.
+func scanWhile(runes []rune, start int, accept func(rune) bool) int {
+	end := start
+
+	for end < len(runes) && accept(runes[end]) {
+		end++
+	}
+
+	return end
+}
+
+// scanString returns the index just past the quoted literal starting at start.
+func scanString(runes []rune, start int) (int, error) {
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+
+	return 0, patternErrorf(start+1, "unterminated literal")
+}
+
+// scanClass returns the index just past the character class starting at start.
+func scanClass(runes []rune, start int) (int, error) {
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case ']':
+			return i + 1, nil
+		}
+	}
+
+	return 0, patternErrorf(start+1, "missing ']' for '['")
+}
+
+// dslParser builds tokens from the items of a DSL string.
+type dslParser struct {
+	builder *PatternBuilder
+	items   []item
+	pos     int
+}
+
+// peek returns the next item without consuming it.
+func (p *dslParser) peek() item {
+	return p.items[p.pos]
+}
+
+// next consumes and returns the next item.
+func (p *dslParser) next() item {
+	it := p.items[p.pos]
+
+	if it.kind != itemEOF {
+		p.pos++
+	}
+
+	return it
+}
+
+// accept consumes the next item if it has the given kind and is not preceded by whitespace.
+func (p *dslParser) accept(kind itemKind) (item, bool) {
+	if it := p.peek(); it.kind == kind && !it.spaced {
+		return p.next(), true
+	}
+
+	return item{}, false
+}
+
+// parseDSL parses a DSL string into tokens.
+func (pb *PatternBuilder) parseDSL(dsl string) ([]Token, error) {
+	items, err := lexPattern(dsl)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &dslParser{builder: pb, items: items}
+
+	tokens, err := parser.parseSequence()
+	if err != nil {
+		return nil, err
+	}
+
+	if it := parser.peek(); it.kind != itemEOF {
+		return nil, patternErrorf(it.column, "unexpected %s", it.kind)
+	}
+
+	if expandedSize(&SequenceToken{Tokens: tokens}) > maxExpandedSize {
+		return nil, patternErrorf(1, "pattern expands to more than %d elements", maxExpandedSize)
+	}
+
+	return tokens, nil
+}
+
+// parseSequence parses alternations up to the end of the pattern or a closing parenthesis.
+func (p *dslParser) parseSequence() ([]Token, error) {
+	var tokens []Token
+
+	for {
+		switch p.peek().kind {
+		case itemEOF, itemRParen:
+			return tokens, nil
+		default:
+			token, err := p.parseAlternation()
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token)
+		}
+	}
+}
+
+// parseAlternation parses one or more postfix elements separated by "|".
+// Alternation binds tighter than sequencing: "W D|S" is a word followed by a digit or symbol.
+//
+//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
+func (p *dslParser) parseAlternation() (Token, error) {
+	first, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+
+	options := []Token{first}
+
+	for p.peek().kind == itemPipe {
+		p.next()
+
+		option, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, option)
+	}
+
+	if len(options) == 1 {
+		return first, nil
+	}
+
+	return &ChoiceToken{Options: options}, nil
+}
+
+// parsePostfix parses an atom followed by any number of "{n}" and "?" quantifiers.
+//
+//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
+func (p *dslParser) parsePostfix() (Token, error) {
+	token, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		counted := p.items[p.pos-1].kind == itemCount
+
+		if it, ok := p.accept(itemCount); ok {
+			// A count directly after another, as in "D{2}{3}" or "W{2}{3}", is ambiguous
+			if counted {
+				return nil, patternErrorf(it.column, "count {%s} directly after another count, use a group, as in (D{2}){3}",
+					it.value)
+			}
+
+			count, err := parseCount(it)
+			if err != nil {
+				return nil, err
+			}
+
+			token = &RepeatToken{Token: token, Count: count}
+
+			if expandedSize(token) > maxExpandedSize {
+				return nil, patternErrorf(it.column, "repeat expands to more than %d elements", maxExpandedSize)
+			}
+
+			continue
+		}
+
+		if _, ok := p.accept(itemOptional); ok {
+			token = &OptionalToken{Token: token}
+
+			continue
+		}
+
+		return token, nil
+	}
+}
+
+// parseAtom parses a single element, literal or group.
+//
+//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
+func (p *dslParser) parseAtom() (Token, error) {
+	it := p.next()
+
+	switch it.kind {
+	case itemLParen:
+		return p.parseGroup(it)
+	case itemString:
+		value, err := unquote(it)
+		if err != nil {
+			return nil, err
+		}
+
+		return &LiteralToken{Value: value}, nil
+	case itemIdent:
+		return p.parseElement(it)
+	case itemClass:
+		charset, err := parseCharClass(it.value)
+		if err != nil {
+			return nil, &PatternError{Column: it.column, Err: err}
+		}
+
+		return p.parseCharset(it, charset)
+	case itemEOF:
+		return nil, patternErrorf(it.column, "unexpected end of pattern")
+	case itemName, itemRange, itemStyle, itemCount, itemOptional:
+		return nil, patternErrorf(it.column, "unexpected %s (modifiers must directly follow their element)", it.kind)
+	default:
+		return nil, patternErrorf(it.column, "unexpected %s", it.kind)
+	}
+}
+
+// parseGroup parses the contents of "( ... )" after the opening parenthesis.
+//
+//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
+func (p *dslParser) parseGroup(open item) (Token, error) {
+	tokens, err := p.parseSequence()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != itemRParen {
+		return nil, patternErrorf(p.peek().column, "missing ')' for '(' at column %d", open.column)
+	}
+
+	p.next()
+
+	switch len(tokens) {
+	case 0:
+		return nil, patternErrorf(open.column, "empty group")
+	case 1:
+		return tokens[0], nil
+	default:
+		return &SequenceToken{Tokens: tokens}, nil
+	}
+}
+
+// parseElement parses a named element (SEP, SHUFFLE, INSIDE, W, P, D, S, C, X, B32 or BIP39) and its modifiers.
+//
+//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
+func (p *dslParser) parseElement(it item) (Token, error) {
+	switch {
+	case it.value == "SEP":
+		return p.parseSeparator()
+	case it.value == "SHUFFLE", it.value == "INSIDE":
+		return p.parsePlacement(it)
+	case it.value == "W":
+		return p.parseWord(it)
+	case strings.Trim(it.value, "P") == "":
+		return p.parseSyllables(it)
+	case strings.Trim(it.value, "D") == "":
+		count, err := p.parseRunCount(it)
+		if err != nil {
+			return nil, err
+		}
+
+		return &DigitToken{Count: count}, nil
+	case strings.Trim(it.value, "S") == "":
+		count, err := p.parseRunCount(it)
+		if err != nil {
+			return nil, err
+		}
+
+		return &SymbolToken{Count: count, Charset: DefaultSymbolCharset}, nil
+	case strings.Trim(it.value, "C") == "":
+		return p.parseCharset(it, AlphanumericCharset)
+	case strings.Trim(it.value, "X") == "":
+		return p.parseCharset(it, HexCharset)
+	case it.value == "B32":
+		return p.parseCharset(it, Base32Charset)
+	case it.value == "BIP39":
+		return p.parseMnemonic(it)
+	default:
+		return nil, patternErrorf(it.column,
+			"unknown element %q (expected W, P, D, S, C, X, B32, BIP39, SEP, SHUFFLE, INSIDE, a [class], "+
+				"a \"literal\" or a group)",
+			it.value)
+	}
+}
+
+// parseCharset creates a character token for a class element such as "C{16}" or "[a-f]{8}".
+//
+//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
+func (p *dslParser) parseCharset(it item, charset string) (Token, error) {
+	count := 1
+
+	// Letter runs such as "XXXX" count their letters; "B32" and classes are a single character.
+	if it.kind == itemIdent && it.value != "B32" {
+		var err error
+
+		if count, err = p.parseRunCount(it); err != nil {
+			return nil, err
+		}
+	} else if countItem, ok := p.accept(itemCount); ok {
+		var err error
+
+		count, err = parseCount(countItem)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &CharsetToken{Charset: charset, Count: count}, nil
+}
+
+// parseMnemonic parses "BIP39" or "BIP39{bits}", a BIP39 mnemonic of 128 bits by default.
+//
+//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
+func (p *dslParser) parseMnemonic(it item) (Token, error) {
+	bits := DefaultMnemonicBits
+
+	if countItem, ok := p.accept(itemCount); ok {
+		var err error
+
+		bits, err = parseCount(countItem)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateMnemonicBits(bits); err != nil {
+		return nil, patternErrorf(it.column, "%v", err)
+	}
+
+	return &MnemonicToken{Bits: bits}, nil
+}
+
+// parseSeparator parses "SEP" or "SEP(\"value\")".
+//
+//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
+func (p *dslParser) parseSeparator() (Token, error) {
+	open, ok := p.accept(itemLParen)
+	if !ok {
+		return &SeparatorToken{Value: p.builder.defaultSep}, nil
+	}
+
+	literal := p.next()
+	if literal.kind != itemString {
+		return nil, patternErrorf(literal.column, "SEP expects a quoted value, as in SEP(\".\")")
+	}
+
+	value, err := unquote(literal)
+	if err != nil {
+		return nil, err
+	}
+
+	if closing := p.next(); closing.kind != itemRParen {
+		return nil, patternErrorf(closing.column, "missing ')' for '(' at column %d", open.column)
+	}
+
+	return &SeparatorToken{Value: value}, nil
+}
+
+// parsePlacement parses SHUFFLE(...) or INSIDE(...) with an optional leading separator,
+// as in SHUFFLE("-", W W D S). The default separator is used if none is given.
+//
+//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
+func (p *dslParser) parsePlacement(element item) (Token, error) {
+	open, ok := p.accept(itemLParen)
+	if !ok {
+		return nil, patternErrorf(element.column, "%s expects its elements in parentheses, as in %s(W W D S)",
+			element.value, element.value)
+	}
+
+	separator := p.builder.defaultSep
+
+	if p.peek().kind == itemString && p.items[p.pos+1].kind == itemComma {
+		value, err := unquote(p.next())
+		if err != nil {
+			return nil, err
+		}
+
+		p.next()
+
+		separator = value
+	}
+
+	tokens, err := p.parseSequence()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != itemRParen {
+		return nil, patternErrorf(p.peek().column, "missing ')' for '(' at column %d", open.column)
+	}
+
+	p.next()
+
+	if len(tokens) == 0 {
+		return nil, patternErrorf(open.column, "empty %s", element.value)
+	}
+
+	if element.value == "SHUFFLE" {
+		return &ShuffleToken{Separator: separator, Tokens: tokens}, nil
+	}
+
+	inside := &InsideToken{Separator: separator, Tokens: tokens}
+	if err := inside.validate(); err != nil {
+		return nil, &PatternError{Column: element.column, Err: err}
+	}
+
+	return inside, nil
+}
+
+// parseWord parses the optional "@name", "<min-max>", "(filters)" and ":style" modifiers of a word.
+// The optional @name picks a dictionary by name (built-in, user dictionary or path)
+// instead of the default one. The optional <min-max> bounds restrict the dictionary
+// to words of that length; either bound may be omitted, as in "W<4->" or "W<-6>".
+// The optional filters restrict it further, as in W(include="^[a-m]+$", blocklist="offensive.txt").
+//
+//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
+func (p *dslParser) parseWord(word item) (Token, error) {
+	var (
+		source    string
+		blocklist string
+		filter    dictionary.FilterOptions
+	)
+
+	if name, ok := p.accept(itemName); ok {
+		source = name.value
+	}
+
+	if bounds, ok := p.accept(itemRange); ok {
+		matches := wordRangePattern.FindStringSubmatch(bounds.value)
+		if matches == nil {
+			return nil, patternErrorf(bounds.column, "invalid word length range %q, expected <min-max>", bounds.value)
+		}
+
+		for i, bound := range []*int{&filter.MinLength, &filter.MaxLength} {
+			if matches[i+1] == "" {
+				continue
+			}
+
+			value, err := strconv.Atoi(matches[i+1])
+			if err != nil {
+				return nil, patternErrorf(bounds.column, "invalid word length %q: %w", matches[i+1], err)
+			}
+
+			*bound = value
+		}
+	}
+
+	if open, ok := p.accept(itemLParen); ok {
+		var err error
+
+		if blocklist, err = p.parseWordFilters(open, &filter); err != nil {
+			return nil, err
+		}
+	}
+
+	caseStyle, err := p.parseStyle()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.builder.wordToken(source, filter, blocklist, caseStyle)
+	if err != nil {
+		return nil, &PatternError{Column: word.column, Err: err}
+	}
+
+	return token, nil
+}
+
+// parseWordFilters parses the "key=value" filters of a word after the opening parenthesis,
+// compiling the include and exclude expressions into filter and loading the blocklist file.
+// It returns the path of the blocklist, empty if none was given.
+func (p *dslParser) parseWordFilters(open item, filter *dictionary.FilterOptions) (string, error) {
+	var blocklist string
+
+	seen := make(map[string]bool)
+
+	for {
+		key := p.next()
+		if key.kind != itemIdent {
+			return "", patternErrorf(key.column, "expected include, exclude or blocklist in word filter")
+		}
+
+		if seen[key.value] {
+			return "", patternErrorf(key.column, "duplicate word filter %q", key.value)
+		}
+
+		seen[key.value] = true
+
+		if equals := p.next(); equals.kind != itemEquals {
+			return "", patternErrorf(equals.column, "expected '=' after %s", key.value)
+		}
+
+		literal := p.next()
+		if literal.kind != itemString {
+			return "", patternErrorf(literal.column, "%s expects a quoted value, as in %s=\"...\"", key.value, key.value)
+		}
+
+		value, err := unquote(literal)
+		if err != nil {
+			return "", err
+		}
+
+		switch key.value {
+		case "include", "exclude":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return "", patternErrorf(literal.column, "invalid %s regex: %v", key.value, err)
+			}
+
+			if key.value == "include" {
+				filter.Include = re
+			} else {
+				filter.Exclude = re
+			}
+		case "blocklist":
+			if filter.Blocklist, err = dictionary.LoadBlocklist(value); err != nil {
+				return "", &PatternError{Column: literal.column, Err: err}
+			}
+
+			blocklist = value
+		default:
+			return "", patternErrorf(key.column, "unknown word filter %q (expected include, exclude or blocklist)",
+				key.value)
+		}
+
+		switch closing := p.next(); closing.kind {
+		case itemComma:
+			continue
+		case itemRParen:
+			return blocklist, nil
+		default:
+			return "", patternErrorf(closing.column, "missing ')' for '(' at column %d", open.column)
+		}
+	}
+}
+
+// parseSyllables parses a pronounceable element such as "P", "PPP" or "P{3}:title".
+//
+//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
+func (p *dslParser) parseSyllables(run item) (Token, error) {
+	count, err := p.parseRunCount(run)
+	if err != nil {
+		return nil, err
+	}
+
+	caseStyle, err := p.parseStyle()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SyllableToken{Count: count, Casing: caseStyle}, nil
+}
+
+// parseStyle parses an optional ":style" modifier, defaulting to lowercase.
+func (p *dslParser) parseStyle() (CaseStyle, error) {
+	style, ok := p.accept(itemStyle)
+	if !ok {
+		return CaseLower, nil
+	}
+
+	caseStyle, err := ParseCaseStyle(style.value)
+	if err != nil {
+		return CaseLower, &PatternError{Column: style.column, Err: err}
+	}
+
+	return caseStyle, nil
+}
+
+// parseRunCount returns the count of a run of letters such as "DDD": the number of letters,
+// or n if the run is directly followed by "{n}" ("D{3}"). As in the first pattern syntax,
+// the count replaces the run's length, so "DD{2}" is two digits and "SS{1}" a single symbol.
+func (p *dslParser) parseRunCount(run item) (int, error) {
+	it, ok := p.accept(itemCount)
+	if !ok {
+		return len(run.value), nil
+	}
+
+	return parseCount(it)
+}
+
+// parseCount parses the value of a "{n}" quantifier.
+func parseCount(it item) (int, error) {
+	count, err := strconv.Atoi(strings.TrimSpace(it.value))
+	if err != nil {
+		return 0, patternErrorf(it.column, "invalid count {%s}, expected a number", it.value)
+	}
+
+	if count <= 0 || count > maxQuantifier {
+		return 0, patternErrorf(it.column, "count must be between 1 and %d, got %d", maxQuantifier, count)
+	}
+
+	return count, nil
+}
+
+// unquote returns the value of a quoted literal item, resolving Go-style escapes.
+func unquote(it item) (string, error) {
+	value, err := strconv.Unquote(it.value)
+	if err != nil {
+		return "", patternErrorf(it.column, "invalid literal %s", it.value)
+	}
+
+	return value, nil
+}
+
+// expandedSize returns the number of elements token generates with its repeats expanded:
+// characters of digit, symbol and character tokens, syllables, and one for any other token.
+// It stops counting beyond maxExpandedSize.
+func expandedSize(token Token) int {
+	switch t := token.(type) {
+	case *DigitToken:
+		return t.Count
+	case *SymbolToken:
+		return t.Count
+	case *CharsetToken:
+		return t.Count
+	case *SyllableToken:
+		return t.Count
+	case *SequenceToken:
+		return expandedTotal(t.Tokens)
+	case *ShuffleToken:
+		return expandedTotal(t.Tokens)
+	case *InsideToken:
+		return expandedTotal(t.Tokens)
+	case *RepeatToken:
+		return min(t.Count*expandedSize(t.Token), maxExpandedSize+1)
+	case *OptionalToken:
+		return expandedSize(t.Token)
+	case *ChoiceToken:
+		largest := 0
+
+		for _, option := range t.Options {
+			largest = max(largest, expandedSize(option))
+		}
+
+		return largest
+	default:
+		return 1
+	}
+}
+
+// expandedTotal returns the summed expandedSize of tokens, stopping beyond maxExpandedSize.
+func expandedTotal(tokens []Token) int {
+	total := 0
+
+	for _, token := range tokens {
+		total = min(total+expandedSize(token), maxExpandedSize+1)
+	}
+
+	return total
+}
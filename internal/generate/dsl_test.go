@@ -0,0 +1,51 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+)
+
+func TestBuildFromDSLCounts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		// want is the pattern as rendered back, empty if an error is expected.
+		want string
+	}{
+		{pattern: "DDD", want: "D{3}"},
+		{pattern: "D{3}", want: "D{3}"},
+		{pattern: "DD{2}", want: "D{2}"},
+		{pattern: "DD{3}", want: "D{3}"},
+		{pattern: "SS{1}", want: "S"},
+		{pattern: "(D{2}){3}", want: "(D{2}){3}"},
+		{pattern: "W{2}", want: "W{2}"},
+		{pattern: "(W{2}){3}", want: "(W{2}){3}"},
+		{pattern: "(BIP39){2}", want: "(BIP39{128}){2}"},
+		{pattern: "D{2}?", want: "D{2}?"},
+		{pattern: "D{2}{3}"},
+		{pattern: "DD{2}{3}"},
+		{pattern: "W{2}{3}"},
+		{pattern: "(W SEP){2}{3}"},
+		{pattern: "[ab]{2}{2}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			t.Parallel()
+
+			pattern, err := NewPatternBuilder(dictionary.EFF(), "-").BuildFromDSL(test.pattern)
+
+			switch {
+			case test.want == "" && err == nil:
+				t.Errorf("BuildFromDSL(%q) = %q, want an error", test.pattern, pattern.String())
+			case test.want == "":
+			case err != nil:
+				t.Errorf("BuildFromDSL(%q) error = %v", test.pattern, err)
+			case pattern.String() != test.want:
+				t.Errorf("BuildFromDSL(%q) = %q, want %q", test.pattern, pattern.String(), test.want)
+			}
+		})
+	}
+}
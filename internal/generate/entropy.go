@@ -3,9 +3,14 @@ package generate
 
 import (
 	"math"
-	"regexp"
+	"slices"
 	"strings"
+	"time"
 	"unicode"
+	"unicode/utf8"
+
+	"github.com/idelchi/pwgen/internal/breach"
+	"github.com/idelchi/pwgen/internal/dictionary"
 )
 
 const (
@@ -15,14 +20,6 @@ const (
 	symbolCharsetSize = 32
 	spaceCharsetSize  = 1
 
-	// Pattern detection constants.
-	minSequenceLength           = 3
-	minRepetitionLength         = 3
-	sequencePenaltyMultiplier   = 2.0
-	repetitionPenaltyMultiplier = 3.0
-	dictionaryPenaltyMultiplier = 1.5
-	yearPatternPenalty          = 8.0
-
 	// Word detection constants.
 	minWordLikeRatio = 0.6
 	minLetterRatio   = 0.7
@@ -35,13 +32,38 @@ const (
 )
 
 // EntropyCalculator calculates entropy for existing passphrases.
-type EntropyCalculator struct{}
+//
+// The entropy is log2 of the guesses an attacker needs, estimated in the manner of zxcvbn:
+// matchers find the parts of the passphrase that are words of the frequency lists (also reversed
+// or with l33t substitutions), keyboard walks, repeats, sequences and dates, and the cheapest
+// decomposition into such matches, with brute force in the gaps, gives the guesses.
+//
+// Passphrases that pwgen could have generated from the pattern dictionaries are also recognized,
+// and the entropy of that pattern is reported alongside.
+type EntropyCalculator struct {
+	ranked       []rankedDict
+	patternDicts []dictionary.Dictionary
+}
 
 // NewEntropyCalculator creates a new entropy calculator.
 func NewEntropyCalculator() *EntropyCalculator {
 	return &EntropyCalculator{}
 }
 
+// SetDictionary makes the calculator recognize words from dict.
+// Its words are equally likely, so each is scored by the dictionary's entropy per word.
+// It also becomes the only dictionary pwgen patterns are recognized with.
+func (ec *EntropyCalculator) SetDictionary(dict dictionary.Dictionary) {
+	ec.ranked = []rankedDict{newRankedDict(dict.Name(), dict.Words(), true)}
+	ec.patternDicts = []dictionary.Dictionary{dict}
+}
+
+// SetPatternDictionaries sets the dictionaries whose pwgen patterns are recognized,
+// such as all registered ones.
+func (ec *EntropyCalculator) SetPatternDictionaries(dicts []dictionary.Dictionary) {
+	ec.patternDicts = dicts
+}
+
 // CharsetInfo represents information about a character set.
 type CharsetInfo struct {
 	Name  string
@@ -92,15 +114,25 @@ type AnalysisResult struct {
 	Patterns       []PatternMatch `json:"patterns,omitempty"`
 	WordBased      bool           `json:"wordBased"`
 	EstimatedWords int            `json:"estimatedWords,omitempty"`
+	// Recognized is the pwgen pattern that generates the passphrase, if any.
+	Recognized *RecognizedPattern `json:"recognized,omitempty"`
+	// Breach is the result of looking the passphrase up in a breached-password list, if requested.
+	Breach *breach.Result `json:"breach,omitempty"`
+	// Mnemonic is the result of validating the passphrase as a BIP39 mnemonic, if requested.
+	Mnemonic *MnemonicCheck `json:"mnemonic,omitempty"`
 }
 
-// PatternMatch represents a detected pattern in the passphrase.
+// PatternMatch represents a detected pattern in the passphrase: one part of the cheapest way to guess it.
 type PatternMatch struct {
-	Type        string  `json:"type"`
-	Description string  `json:"description"`
-	Position    int     `json:"position"`
-	Length      int     `json:"length"`
-	Penalty     float64 `json:"entropyPenalty"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Position    int    `json:"position"`
+	Length      int    `json:"length"`
+	Token       string `json:"token"`
+	// Entropy is log2 of the guesses needed for this part on its own.
+	Entropy float64 `json:"entropy"`
+	// Penalty is how much less entropy this part has than random characters of the passphrase's sets.
+	Penalty float64 `json:"entropyPenalty"`
 }
 
 // CalculateEntropy performs detailed entropy analysis of a passphrase.
@@ -119,19 +151,54 @@ func (ec *EntropyCalculator) CalculateEntropy(passphrase string) AnalysisResult
 	charsets := ec.detectCharsets(passphrase)
 	charsetSize := ec.calculateCharsetSize(charsets)
 
-	// Calculate base entropy
-	baseEntropy := float64(len(passphrase)) * math.Log2(float64(charsetSize))
+	length := utf8.RuneCountInString(passphrase)
+	bitsPerChar := math.Log2(float64(charsetSize))
+
+	// Find the cheapest way to guess it
+	guess := ec.matcher(bitsPerChar).estimate([]rune(passphrase))
+	patterns := make([]PatternMatch, 0, len(guess.sequence))
+	knownWords := 0
+
+	for _, found := range guess.sequence {
+		size := found.j - found.i + 1
+
+		patterns = append(patterns, PatternMatch{
+			Type:        found.kind,
+			Description: found.describe(),
+			Position:    found.i,
+			Length:      size,
+			Token:       found.token,
+			Entropy:     found.bits,
+			Penalty:     max(float64(size)*bitsPerChar-found.bits, 0),
+		})
+
+		if found.kind == matchDictionary && found.uniform {
+			knownWords++
+		}
+	}
 
-	// Detect patterns and apply penalties
-	patterns := ec.detectPatterns(passphrase)
-	adjustedEntropy := ec.applyPatternPenalties(baseEntropy, patterns)
+	adjustedEntropy := guess.bits
 
 	// Check if it looks word-based
 	wordBased, estimatedWords := ec.analyzeWordStructure(passphrase)
+	if knownWords > 0 {
+		wordBased, estimatedWords = true, knownWords
+	}
+
+	// A pattern that costs more than guessing does not explain the passphrase: "Password2024!" splits
+	// into dictionary words, digits and a symbol, but it is a common password.
+	recognized := recognizePattern(passphrase, ec.patternDicts)
+	if recognized != nil && recognized.Entropy >= guess.bits {
+		recognized = nil
+	}
+
+	if recognized != nil {
+		wordBased, estimatedWords = true, recognized.Words
+	}
 
 	result := AnalysisResult{
 		Passphrase:     passphrase,
-		Length:         len(passphrase),
+		Length:         length,
 		Entropy:        adjustedEntropy,
 		CharsetSize:    charsetSize,
 		Charsets:       ec.charsetNames(charsets),
@@ -140,6 +207,7 @@ func (ec *EntropyCalculator) CalculateEntropy(passphrase string) AnalysisResult
 		Patterns:       patterns,
 		WordBased:      wordBased,
 		EstimatedWords: estimatedWords,
+		Recognized:     recognized,
 	}
 
 	return result
@@ -214,160 +282,14 @@ func (ec *EntropyCalculator) charsetNames(charsets []CharsetInfo) []string {
 	return names
 }
 
-// detectPatterns finds common patterns that reduce entropy.
-func (ec *EntropyCalculator) detectPatterns(str string) []PatternMatch {
-	var patterns []PatternMatch
-
-	// Sequential patterns (123, abc, etc.)
-	patterns = append(patterns, ec.findSequentialPatterns(str)...)
-
-	// Repetition patterns (aaa, 111, etc.)
-	patterns = append(patterns, ec.findRepetitionPatterns(str)...)
-
-	// Dictionary word patterns (common words)
-	patterns = append(patterns, ec.findDictionaryPatterns(str)...)
-
-	// Date patterns (1234, 2023, etc.)
-	patterns = append(patterns, ec.findDatePatterns(str)...)
-
-	return patterns
-}
-
-// findSequentialPatterns finds sequential character patterns.
-func (ec *EntropyCalculator) findSequentialPatterns(str string) []PatternMatch {
-	var patterns []PatternMatch
-
-	// Look for sequences of 3+ characters.
-	for i := 0; i < len(str)-minSequenceLength+1; i++ { //nolint:varnamelen // i is standard loop var
-		seqLen := ec.getSequenceLength(str, i)
-		if seqLen >= minSequenceLength {
-			patterns = append(patterns, PatternMatch{
-				Type:        "sequential",
-				Description: "Sequential pattern: " + str[i:i+seqLen],
-				Position:    i,
-				Length:      seqLen,
-				Penalty:     float64(seqLen) * sequencePenaltyMultiplier, // Reduce entropy significantly
-			})
-
-			i += seqLen - 1 // Skip past this pattern
-		}
-	}
-
-	return patterns
-}
-
-// getSequenceLength returns the length of a sequential pattern starting at pos.
-func (ec *EntropyCalculator) getSequenceLength(str string, pos int) int {
-	if pos+minSequenceLength-1 >= len(str) {
-		return 0
+// matcher returns the pattern matcher for the frequency lists and the configured dictionary,
+// brute-forcing unmatched characters at bitsPerChar.
+func (ec *EntropyCalculator) matcher(bitsPerChar float64) *matcher {
+	return &matcher{
+		dicts:          append(slices.Clone(frequencyDicts()), ec.ranked...),
+		referenceYear:  time.Now().Year(),
+		bruteforceBits: bitsPerChar,
 	}
-
-	length := minSequenceLength - 1
-	for pos+length < len(str) {
-		// Check if characters are sequential
-		if str[pos+length] != str[pos+length-1]+1 {
-			break
-		}
-
-		length++
-	}
-
-	return length
-}
-
-// findRepetitionPatterns finds repeated character patterns.
-func (ec *EntropyCalculator) findRepetitionPatterns(str string) []PatternMatch {
-	var patterns []PatternMatch
-
-	// Find sequences of 3+ identical characters.
-	for i := 0; i < len(str)-minRepetitionLength+1; i++ { //nolint:varnamelen // i is standard loop var
-		if str[i] == str[i+1] && str[i+1] == str[i+minRepetitionLength-1] {
-			// Found start of repetition, find end
-			j := i + minRepetitionLength - 1 //nolint:varnamelen // j is temp var
-			for j < len(str) && str[j] == str[i] {
-				j++
-			}
-
-			length := j - i
-
-			patterns = append(patterns, PatternMatch{
-				Type:        "repetition",
-				Description: "Repeated character: " + str[i:j],
-				Position:    i,
-				Length:      length,
-				Penalty:     float64(length-1) * repetitionPenaltyMultiplier, // Heavy penalty for repetition
-			})
-
-			i = j - 1 // Skip past this repetition
-		}
-	}
-
-	return patterns
-}
-
-// findDictionaryPatterns finds common dictionary words.
-func (ec *EntropyCalculator) findDictionaryPatterns(str string) []PatternMatch {
-	var patterns []PatternMatch
-
-	// Simple check for common words (this could be expanded)
-	commonWords := []string{
-		"password", "admin", "user", "login", "welcome",
-		"hello", "world", "test", "demo", "sample",
-		"the", "and", "you", "that", "was", "for", "are",
-	}
-
-	lower := strings.ToLower(str)
-
-	for _, word := range commonWords {
-		if idx := strings.Index(lower, word); idx != -1 {
-			patterns = append(patterns, PatternMatch{
-				Type:        "dictionary",
-				Description: "Common word: " + word,
-				Position:    idx,
-				Length:      len(word),
-				Penalty:     float64(len(word)) * dictionaryPenaltyMultiplier, // Moderate penalty
-			})
-		}
-	}
-
-	return patterns
-}
-
-// findDatePatterns finds date-like patterns.
-func (ec *EntropyCalculator) findDatePatterns(str string) []PatternMatch {
-	// Look for 4-digit years
-	re := regexp.MustCompile(`\b(19|20)\d{2}\b`)
-	matches := re.FindAllStringIndex(str, -1)
-
-	patterns := make([]PatternMatch, 0, len(matches))
-
-	for _, match := range matches {
-		patterns = append(patterns, PatternMatch{
-			Type:        "date",
-			Description: "Year pattern: " + str[match[0]:match[1]],
-			Position:    match[0],
-			Length:      match[1] - match[0],
-			Penalty:     yearPatternPenalty, // Years have limited entropy
-		})
-	}
-
-	return patterns
-}
-
-// applyPatternPenalties reduces entropy based on detected patterns.
-func (ec *EntropyCalculator) applyPatternPenalties(baseEntropy float64, patterns []PatternMatch) float64 {
-	totalPenalty := 0.0
-
-	for _, pattern := range patterns {
-		totalPenalty += pattern.Penalty
-	}
-
-	adjusted := baseEntropy - totalPenalty
-	if adjusted < 0 {
-		adjusted = 0
-	}
-
-	return adjusted
 }
 
 // analyzeWordStructure determines if the passphrase appears to be word-based.
@@ -431,7 +353,8 @@ func (ec *EntropyCalculator) hasAlternatingCase(str string) bool {
 
 // isWordLike checks if a string segment resembles a word.
 func (ec *EntropyCalculator) isWordLike(str string) bool {
-	if len(str) < minWordLength || len(str) > maxWordLength {
+	length := utf8.RuneCountInString(str)
+	if length < minWordLength || length > maxWordLength {
 		return false
 	}
 
@@ -444,7 +367,7 @@ func (ec *EntropyCalculator) isWordLike(str string) bool {
 		}
 	}
 
-	return float64(letterCount)/float64(len(str)) > minLetterRatio
+	return float64(letterCount)/float64(length) > minLetterRatio
 }
 
 // countWords estimates the number of words in a word-based passphrase.
@@ -470,7 +393,7 @@ func (ec *EntropyCalculator) countWords(str, sep string) int {
 // estimateConcatenatedWords estimates word count in concatenated strings.
 func (ec *EntropyCalculator) estimateConcatenatedWords(str string) int {
 	// Simple heuristic: assume average word length of 5-6 characters
-	estimatedWords := int(float64(len(str)) / avgWordLength)
+	estimatedWords := int(float64(utf8.RuneCountInString(str)) / avgWordLength)
 
 	if estimatedWords < 1 {
 		estimatedWords = 1
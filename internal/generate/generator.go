@@ -1,7 +1,10 @@
 package generate
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"unicode/utf8"
 
 	"github.com/idelchi/pwgen/internal/dictionary"
 )
@@ -44,13 +47,27 @@ type Options struct {
 	Symbols    int
 	Separator  string
 	Casing     string
+	Placement  string
 	Pattern    string
+	Chars      int
+	Hex        int
 	Kebab      bool
 	Snake      bool
 	Camel      bool
 	Count      int
 	MinEntropy int
 	MinLength  int
+	MaxLength  int
+	// Policy, if set, holds the rules every passphrase must follow.
+	// MinLength, MaxLength and MinEntropy tighten its rules of the same name.
+	Policy *Policy
+	// ShowRolls reports the dice roll of every word picked, for diceware dictionaries only.
+	ShowRolls bool
+	// Mnemonic, if set, is the entropy in bits of a BIP39 mnemonic to generate instead of words.
+	Mnemonic int
+	// Exclude, if set, reports passphrases to discard and generate again, such as those seen in a breach.
+	// Unlike the policy, it does not reduce the entropy: it should exclude a negligible share of the outcomes.
+	Exclude func(passphrase string) (bool, error)
 }
 
 // Result represents a generated passphrase with metadata.
@@ -62,28 +79,15 @@ type Result struct {
 	Strength   string  `json:"strength"`
 	CrackTime  string  `json:"crackTime"`
 	PolicyPass bool    `json:"policyPass"`
+	// Rolls are the diceware rolls of the words, in order, if requested or entered.
+	Rolls []string `json:"rolls,omitempty"`
 }
 
 // Generate creates one or more passphrases based on the given options.
 func (g *Generator) Generate(opts Options) ([]Result, error) {
-	// Build pattern from options or DSL
-	var (
-		pattern *Pattern
-		err     error
-	)
-
-	if opts.Pattern != "" {
-		pattern, err = g.patternBuilder.BuildFromDSL(opts.Pattern)
-	} else {
-		pattern, err = g.patternBuilder.BuildFromOptions(
-			opts.Words, opts.Digits, opts.Symbols,
-			opts.Casing, opts.Separator,
-			opts.Kebab, opts.Snake, opts.Camel,
-		)
-	}
-
+	pattern, err := g.BuildPattern(opts)
 	if err != nil {
-		return nil, fmt.Errorf("building pattern: %w", err)
+		return nil, err
 	}
 
 	// Generate requested number of passphrases
@@ -93,22 +97,34 @@ func (g *Generator) Generate(opts Options) ([]Result, error) {
 	}
 
 	results := make([]Result, 0, count)
+	policy := opts.policy()
+
+	var rolls *rollLog
+
+	if opts.ShowRolls {
+		rolls = &rollLog{}
+
+		if err := recordRolls(pattern, rolls); err != nil {
+			return nil, err
+		}
+	}
+
+	// Passphrases that break the policy are discarded and generated again,
+	// which leaves fewer possible outcomes and so less entropy.
+	entropy, err := policy.Entropy(pattern)
+	if err != nil {
+		return nil, err
+	}
 
 	for i := range count {
-		passphrase, err := pattern.Generate()
+		passphrase, err := policy.generateCompliant(pattern, rolls, opts.Exclude)
 		if err != nil {
 			return nil, fmt.Errorf("generating passphrase %d: %w", i+1, err)
 		}
 
-		result := Result{
-			Passphrase: passphrase,
-			Entropy:    pattern.EntropyBits(),
-			Length:     len(passphrase),
-			Pattern:    pattern.String(),
-			Strength:   calculateStrength(pattern.EntropyBits()),
-			CrackTime:  estimateCrackTime(pattern.EntropyBits()),
-			PolicyPass: checkPolicy(passphrase, pattern.EntropyBits(), opts.MinLength, opts.MinEntropy),
-		}
+		result := NewResult(passphrase, entropy, pattern)
+		result.PolicyPass = policy.Allows(passphrase) && entropy >= float64(policy.MinEntropy)
+		result.Rolls = rolls.take()
 
 		results = append(results, result)
 	}
@@ -116,6 +132,95 @@ func (g *Generator) Generate(opts Options) ([]Result, error) {
 	return results, nil
 }
 
+// NewResult describes a passphrase generated from pattern with the given entropy.
+// PolicyPass is true, as no policy is checked.
+func NewResult(passphrase string, entropy float64, pattern *Pattern) Result {
+	return Result{
+		Passphrase: passphrase,
+		Entropy:    entropy,
+		Length:     utf8.RuneCountInString(passphrase),
+		Pattern:    pattern.String(),
+		Strength:   calculateStrength(entropy),
+		CrackTime:  estimateCrackTime(entropy),
+		PolicyPass: true,
+	}
+}
+
+// policy returns the policy from the options, tightened by their MinLength, MaxLength and MinEntropy.
+func (o Options) policy() *Policy {
+	var policy Policy
+
+	if o.Policy != nil {
+		policy = *o.Policy
+	}
+
+	policy.MinLength = max(policy.MinLength, o.MinLength)
+	policy.MinEntropy = max(policy.MinEntropy, o.MinEntropy)
+
+	if o.MaxLength > 0 && (policy.MaxLength == 0 || o.MaxLength < policy.MaxLength) {
+		policy.MaxLength = o.MaxLength
+	}
+
+	return &policy
+}
+
+// Explain describes the pattern the given options produce, without generating a passphrase.
+func (g *Generator) Explain(opts Options) (Explanation, error) {
+	pattern, err := g.BuildPattern(opts)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	return pattern.Explain(), nil
+}
+
+// BuildPattern builds the pattern from the DSL in opts, a random character string or mnemonic if requested,
+// or from the word options otherwise.
+func (g *Generator) BuildPattern(opts Options) (*Pattern, error) {
+	var (
+		pattern *Pattern
+		err     error
+	)
+
+	switch {
+	case opts.Pattern != "":
+		pattern, err = g.patternBuilder.BuildFromDSL(opts.Pattern)
+	case opts.Chars > 0 && opts.Hex > 0:
+		err = errors.New("random characters and hex cannot be combined")
+	case opts.Mnemonic > 0 && (opts.Chars > 0 || opts.Hex > 0):
+		err = errors.New("a mnemonic cannot be combined with random characters or hex")
+	case opts.Mnemonic > 0:
+		if err = validateMnemonicBits(opts.Mnemonic); err == nil {
+			pattern = &Pattern{
+				Tokens: []Token{&MnemonicToken{Bits: opts.Mnemonic}},
+				Source: g.patternBuilder.source,
+			}
+		}
+	case opts.Chars > 0:
+		pattern = &Pattern{
+			Tokens: []Token{&CharsetToken{Charset: AlphanumericCharset, Count: opts.Chars}},
+			Source: g.patternBuilder.source,
+		}
+	case opts.Hex > 0:
+		pattern = &Pattern{
+			Tokens: []Token{&CharsetToken{Charset: HexCharset, Count: opts.Hex}},
+			Source: g.patternBuilder.source,
+		}
+	default:
+		pattern, err = g.patternBuilder.BuildFromOptions(
+			opts.Words, opts.Digits, opts.Symbols,
+			opts.Casing, opts.Separator, opts.Placement,
+			opts.Kebab, opts.Snake, opts.Camel,
+		)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("building pattern: %w", err)
+	}
+
+	return pattern, nil
+}
+
 // calculateStrength returns a human-readable strength assessment.
 func calculateStrength(entropy float64) string {
 	switch {
@@ -156,17 +261,20 @@ func estimateCrackTime(entropy float64) string {
 	}
 }
 
-// checkPolicy checks if the passphrase meets minimum requirements.
-func checkPolicy(passphrase string, entropy float64, minLength, minEntropy int) bool {
-	if minLength > 0 && len(passphrase) < minLength {
-		return false
-	}
+// SetResolver changes how dictionaries named in patterns ("W@nouns") are looked up.
+func (g *Generator) SetResolver(resolve DictionaryResolver) {
+	g.patternBuilder.SetResolver(resolve)
+}
 
-	if minEntropy > 0 && entropy < float64(minEntropy) {
-		return false
-	}
+// SetWordFilter restricts the words of the generated passphrases, as PatternBuilder.SetWordFilter does.
+func (g *Generator) SetWordFilter(filter dictionary.FilterOptions, blocklist string) {
+	g.patternBuilder.SetWordFilter(filter, blocklist)
+}
 
-	return true
+// SetSource changes where the generator draws its randomness from; nil restores crypto/rand.Reader.
+// A deterministic source, such as one from random.NewSeeded, makes the passphrases reproducible.
+func (g *Generator) SetSource(source io.Reader) {
+	g.patternBuilder.SetSource(source)
 }
 
 // SetDictionary changes the dictionary used by the generator.
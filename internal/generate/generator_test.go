@@ -0,0 +1,134 @@
+package generate
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+	"github.com/idelchi/pwgen/internal/random"
+)
+
+func TestGenerateSeeded(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "default words",
+			opts: Options{Words: 4, Separator: "-", Casing: "mixed", Count: 2},
+			want: []string{"Ion-OPPoNenT-EVASIOn-negATe", "KIlT-SLEet-PAcIFy-MaTted"},
+		},
+		{
+			name: "digits and symbols between the words",
+			opts: Options{Words: 3, Separator: ".", Casing: "title", Digits: 2, Symbols: 1, Placement: "random", Count: 2},
+			want: []string{"Celery.9.Elephant.6.Lurch..", "1.Legacy.Moaning.Action.%.5"},
+		},
+		{
+			name: "pattern with a charset, a repeat and a choice",
+			opts: Options{Pattern: `"sk_" [a-z0-9]{8} (W SEP(".")){2} D{4}|S{2}`, Separator: "-", Count: 2},
+			want: []string{"sk_tuymfz3tdeflector.lurch.6927", "sk_f5lt7sbmcage.fax.1291"},
+		},
+		{
+			name: "random characters",
+			opts: Options{Chars: 16, Count: 2},
+			want: []string{"daaOhjB9aHekl4XN", "pXBru9WYk1Xk3Pjc"},
+		},
+		{
+			name: "mnemonic",
+			opts: Options{Mnemonic: 128, Separator: " ", Casing: "lower", Count: 1},
+			want: []string{"call above kitchen alter vault print slab odor vanish stool fly input"},
+		},
+		{
+			// Estimating the policy's entropy must not draw from the generator's source.
+			name: "policy estimated from samples",
+			opts: Options{Words: 4, Separator: "-", Casing: "mixed", Count: 2, Policy: &Policy{MaxRepeat: 3}},
+			want: []string{"Ion-OPPoNenT-EVASIOn-negATe", "KIlT-SLEet-PAcIFy-MaTted"},
+		},
+		{
+			name: "policy computed exactly",
+			opts: Options{Words: 4, Separator: "-", Casing: "mixed", Count: 2, Policy: &Policy{RequireLower: true}},
+			want: []string{"Ion-OPPoNenT-EVASIOn-negATe", "KIlT-SLEet-PAcIFy-MaTted"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			generator := NewGenerator(dictionary.EFF(), test.opts.Separator)
+			generator.SetSource(random.NewSeeded(1))
+
+			results, err := generator.Generate(test.opts)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			got := make([]string, 0, len(results))
+
+			for _, result := range results {
+				got = append(got, result.Passphrase)
+			}
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Generate() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBuildPatternWithWordFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "words",
+			opts: Options{Words: 2, Separator: "-", Casing: "mixed"},
+			want: `W<3-6>(include="^[a-m]"):mixed SEP("-") W<3-6>(include="^[a-m]"):mixed`,
+		},
+		{
+			name: "pattern words keep their own filters",
+			opts: Options{Pattern: `W<4->(include="^b") W@eff`},
+			want: `W<4-6>(include="^b") W@eff<3-6>(include="^[a-m]")`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			generator := NewGenerator(dictionary.EFF(), "-")
+			generator.SetWordFilter(dictionary.FilterOptions{
+				MinLength: 3,
+				MaxLength: 6,
+				Include:   regexp.MustCompile("^[a-m]"),
+			}, "")
+
+			pattern, err := generator.BuildPattern(test.opts)
+			if err != nil {
+				t.Fatalf("BuildPattern() error = %v", err)
+			}
+
+			if got := pattern.String(); got != test.want {
+				t.Fatalf("BuildPattern() = %q, want %q", got, test.want)
+			}
+
+			// The pattern reproduces the filtered words without the generator's filters.
+			parsed, err := NewPatternBuilder(dictionary.EFF(), "-").BuildFromDSL(pattern.String())
+			if err != nil {
+				t.Fatalf("BuildFromDSL(%q) error = %v", pattern.String(), err)
+			}
+
+			if got, want := parsed.EntropyBits(), pattern.EntropyBits(); got != want {
+				t.Errorf("BuildFromDSL(%q).EntropyBits() = %v, want %v", pattern.String(), got, want)
+			}
+		})
+	}
+}
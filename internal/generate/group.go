@@ -0,0 +1,274 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/idelchi/pwgen/internal/random"
+)
+
+// SequenceToken generates its tokens one after another, as in "(W SEP)".
+type SequenceToken struct {
+	Tokens []Token
+}
+
+// Generate produces the concatenated values of all tokens.
+func (s *SequenceToken) Generate(source io.Reader) (string, error) {
+	var result strings.Builder
+
+	for _, token := range s.Tokens {
+		part, err := token.Generate(source)
+		if err != nil {
+			return "", err
+		}
+
+		result.WriteString(part)
+	}
+
+	return result.String(), nil
+}
+
+// EntropyBits returns the entropy of the concatenated tokens: the sum of their entropies,
+// unless choices or optional elements among them can produce the same output in several ways.
+func (s *SequenceToken) EntropyBits() float64 {
+	return tokensEntropy(s.Tokens)
+}
+
+// Type returns a description of this token type.
+func (s *SequenceToken) Type() string {
+	parts := make([]string, 0, len(s.Tokens))
+
+	for _, token := range s.Tokens {
+		parts = append(parts, token.Type())
+	}
+
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// DSL returns the sequence in pattern DSL syntax, e.g. "(W SEP)".
+func (s *SequenceToken) DSL() string {
+	parts := make([]string, 0, len(s.Tokens))
+
+	for _, token := range s.Tokens {
+		parts = append(parts, token.DSL())
+	}
+
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// RepeatToken generates a token several times, as in "(W SEP){3}".
+type RepeatToken struct {
+	Token Token
+	Count int
+}
+
+// Generate produces Count independent values of the token.
+func (r *RepeatToken) Generate(source io.Reader) (string, error) {
+	if r.Count <= 0 {
+		return "", errors.New("repeat count must be positive")
+	}
+
+	var result strings.Builder
+
+	for range r.Count {
+		part, err := r.Token.Generate(source)
+		if err != nil {
+			return "", err
+		}
+
+		result.WriteString(part)
+	}
+
+	return result.String(), nil
+}
+
+// EntropyBits returns the entropy of Count values of the token: Count times its entropy,
+// unless choices or optional elements in it can produce the same output in several ways, as in "D?{2}".
+func (r *RepeatToken) EntropyBits() float64 {
+	if r.Count <= 0 {
+		return 0
+	}
+
+	return tokensEntropy([]Token{r})
+}
+
+// Type returns a description of this token type.
+func (r *RepeatToken) Type() string {
+	return fmt.Sprintf("%s{%d}", operand(r.Token, r.Token.Type()), r.Count)
+}
+
+// DSL returns the repetition in pattern DSL syntax, e.g. "(W SEP){3}".
+func (r *RepeatToken) DSL() string {
+	text := operand(r.Token, r.Token.DSL())
+
+	// An element takes a single count, so "(D{2}){3}" keeps its parentheses
+	if strings.HasSuffix(text, "}") {
+		text = "(" + text + ")"
+	}
+
+	return fmt.Sprintf("%s{%d}", text, r.Count)
+}
+
+// ChoiceToken generates one of several alternatives, as in "D{4}|S{2}".
+//
+// Alternatives are picked in proportion to the number of outcomes they can produce,
+// so if they never produce the same output every possible output is equally likely and the entropy is
+// log2 of the summed outcome counts rather than that of the weakest alternative.
+// Alternatives that can produce the same output, as in "D|D" or "C|D", count those outputs once.
+type ChoiceToken struct {
+	Options []Token
+}
+
+// Generate produces the value of a randomly picked alternative.
+func (c *ChoiceToken) Generate(source io.Reader) (string, error) {
+	if len(c.Options) == 0 {
+		return "", errors.New("choice has no alternatives")
+	}
+
+	entropies := make([]float64, len(c.Options))
+
+	for i, option := range c.Options {
+		entropies[i] = option.EntropyBits()
+	}
+
+	index, err := weightedChoice(source, entropies)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Options[index].Generate(source)
+}
+
+// EntropyBits returns log2 of the summed outcome counts of all alternatives,
+// or less if alternatives can produce the same output (see tokensEntropy).
+func (c *ChoiceToken) EntropyBits() float64 {
+	return tokensEntropy([]Token{c})
+}
+
+// Type returns a description of this token type.
+func (c *ChoiceToken) Type() string {
+	parts := make([]string, 0, len(c.Options))
+
+	for _, option := range c.Options {
+		parts = append(parts, option.Type())
+	}
+
+	return strings.Join(parts, "|")
+}
+
+// DSL returns the choice in pattern DSL syntax, e.g. "D{4}|S{2}".
+func (c *ChoiceToken) DSL() string {
+	parts := make([]string, 0, len(c.Options))
+
+	for _, option := range c.Options {
+		parts = append(parts, operand(option, option.DSL()))
+	}
+
+	return strings.Join(parts, "|")
+}
+
+// OptionalToken generates its token or nothing, as in "S?".
+//
+// Like a choice between the token and an empty string, the token is included in proportion
+// to its outcome count, giving an entropy of log2(2^bits + 1).
+type OptionalToken struct {
+	Token Token
+}
+
+// Generate produces the value of the token or an empty string.
+func (o *OptionalToken) Generate(source io.Reader) (string, error) {
+	index, err := weightedChoice(source, []float64{o.Token.EntropyBits(), 0})
+	if err != nil {
+		return "", err
+	}
+
+	if index == 1 {
+		return "", nil
+	}
+
+	return o.Token.Generate(source)
+}
+
+// EntropyBits returns log2 of the token's outcome count plus one for the empty outcome,
+// or less if the token itself can be empty.
+func (o *OptionalToken) EntropyBits() float64 {
+	return tokensEntropy([]Token{o})
+}
+
+// Type returns a description of this token type.
+func (o *OptionalToken) Type() string {
+	return operand(o.Token, o.Token.Type()) + "?"
+}
+
+// DSL returns the optional token in pattern DSL syntax, e.g. "S?".
+func (o *OptionalToken) DSL() string {
+	return operand(o.Token, o.Token.DSL()) + "?"
+}
+
+// operand returns text, the description of token, parenthesized if token is a choice,
+// so that "(D|S){4}" is not mistaken for "D|S{4}".
+func operand(token Token, text string) string {
+	if _, ok := token.(*ChoiceToken); ok {
+		return "(" + text + ")"
+	}
+
+	return text
+}
+
+// sumOutcomeBits returns log2(sum(2^bits)) without overflowing for large entropies.
+func sumOutcomeBits(bits []float64) float64 {
+	if len(bits) == 0 {
+		return 0
+	}
+
+	highest := bits[0]
+
+	for _, b := range bits[1:] {
+		highest = max(highest, b)
+	}
+
+	sum := 0.0
+
+	for _, b := range bits {
+		sum += math.Exp2(b - highest)
+	}
+
+	return highest + math.Log2(sum)
+}
+
+// weightedChoice picks an index with probability proportional to 2^bits[i].
+func weightedChoice(source io.Reader, bits []float64) (int, error) {
+	highest := math.Inf(-1)
+
+	for _, b := range bits {
+		highest = max(highest, b)
+	}
+
+	weights := make([]float64, len(bits))
+	total := 0.0
+
+	for i, b := range bits {
+		weights[i] = math.Exp2(b - highest)
+		total += weights[i]
+	}
+
+	fraction, err := random.Float64(source)
+	if err != nil {
+		return 0, fmt.Errorf("generating random choice: %w", err)
+	}
+
+	target := fraction * total
+
+	for i, weight := range weights {
+		if target < weight {
+			return i, nil
+		}
+
+		target -= weight
+	}
+
+	return len(weights) - 1, nil
+}
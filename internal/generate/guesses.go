@@ -0,0 +1,351 @@
+package generate
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// minSubmatchGuessesSingleChar and minSubmatchGuessesMultiChar are the fewest guesses a match
+	// counts for when it is only part of the password, so that splitting into many tiny matches
+	// is not cheaper than it should be.
+	minSubmatchGuessesSingleChar = 10
+	minSubmatchGuessesMultiChar  = 50
+
+	// minGuessesBeforeGrowingSequence is added for every match beyond the first, as an attacker
+	// tries shorter decompositions first.
+	minGuessesBeforeGrowingSequence = 10000
+
+	// maxMatchedLength is the number of leading characters searched for patterns, as in zxcvbn:
+	// the search grows faster than cubically with the length, and the rest is guessed by brute force.
+	maxMatchedLength = 100
+
+	// minYearSpace is the fewest years around the reference year an attacker tries.
+	minYearSpace = 20
+	// daysPerYear is the number of dates an attacker tries per year.
+	daysPerYear = 365
+	// dateSeparators is the number of separators an attacker tries in a date.
+	dateSeparators = 4
+
+	// Starting guesses of sequences: those starting at an end of the alphabet or digits,
+	// other digit sequences and other sequences.
+	obviousSequenceStart = 4
+	digitSequenceStart   = 10
+	otherSequenceStart   = 26
+)
+
+// estimate is the cheapest way to guess a password: a sequence of matches covering it, with gaps
+// filled by brute force, and the bits (log2 of the guesses) it takes.
+type estimate struct {
+	bits     float64
+	sequence []*match
+}
+
+// step is the last match of the cheapest sequence of a given length that covers a prefix of the password,
+// with the bits of the product of its matches' guesses (pi) and of the sequence (g).
+type step struct {
+	match *match
+	pi    float64
+	g     float64
+}
+
+// estimate finds the cheapest decomposition of the password into the matches found in it.
+// Past maxMatchedLength characters, the rest of the password is brute force.
+func (m *matcher) estimate(password []rune) estimate {
+	if len(password) <= maxMatchedLength {
+		return m.decompose(password, m.matches(password))
+	}
+
+	matched := password[:maxMatchedLength]
+	result := m.decompose(matched, m.matches(matched))
+
+	rest := bruteforceMatch(password, maxMatchedLength, len(password)-1)
+	result.bits += m.guessBits(rest, len(password))
+	result.sequence = append(result.sequence, rest)
+
+	return result
+}
+
+// decompose chooses the sequence of non-overlapping matches, with brute force in the gaps, that minimizes
+//
+//	l! * (guesses of match 1) * ... * (guesses of match l) + minGuessesBeforeGrowingSequence^(l-1)
+//
+// for a sequence of l matches, as zxcvbn does: an attacker guessing l patterns tries them in any order.
+// The search is a dynamic program over prefixes of the password and sequence lengths, done in log2.
+func (m *matcher) decompose(password []rune, matches []*match) estimate {
+	length := len(password)
+	if length == 0 {
+		return estimate{}
+	}
+
+	byEnd := make([][]*match, length)
+
+	for _, found := range matches {
+		byEnd[found.j] = append(byEnd[found.j], found)
+	}
+
+	for _, ending := range byEnd {
+		slices.SortStableFunc(ending, func(a, b *match) int { return a.i - b.i })
+	}
+
+	// optimal[k][l] is the last step of the cheapest sequence of l matches covering password[0..k]
+	optimal := make([][]*step, length)
+	for k := range optimal {
+		optimal[k] = make([]*step, length+1)
+	}
+
+	update := func(found *match, l int) {
+		pi := m.guessBits(found, length)
+		if l > 1 {
+			pi += optimal[found.i-1][l-1].pi
+		}
+
+		g := log2Factorial(l) + pi
+		g = log2Sum(g, float64(l-1)*math.Log2(minGuessesBeforeGrowingSequence))
+
+		for competing := 1; competing <= l; competing++ {
+			if other := optimal[found.j][competing]; other != nil && other.g <= g {
+				return
+			}
+		}
+
+		optimal[found.j][l] = &step{match: found, pi: pi, g: g}
+	}
+
+	for k := range length {
+		for _, found := range byEnd[k] {
+			if found.i == 0 {
+				update(found, 1)
+
+				continue
+			}
+
+			for l, last := range optimal[found.i-1] {
+				if last != nil {
+					update(found, l+1)
+				}
+			}
+		}
+
+		// Brute force from any position to k, but never right after more brute force
+		update(bruteforceMatch(password, 0, k), 1)
+
+		for i := 1; i <= k; i++ {
+			for l, last := range optimal[i-1] {
+				if last != nil && last.match.kind != matchBruteforce {
+					update(bruteforceMatch(password, i, k), l+1)
+				}
+			}
+		}
+	}
+
+	// Unwind the cheapest sequence covering the whole password
+	best := 0
+
+	for l, last := range optimal[length-1] {
+		if last != nil && (best == 0 || last.g < optimal[length-1][best].g) {
+			best = l
+		}
+	}
+
+	result := estimate{bits: optimal[length-1][best].g}
+
+	for k, l := length-1, best; k >= 0; l-- {
+		found := optimal[k][l].match
+		result.sequence = append(result.sequence, found)
+		k = found.i - 1
+	}
+
+	slices.Reverse(result.sequence)
+
+	return result
+}
+
+// bruteforceMatch covers password[i..j] with brute force.
+func bruteforceMatch(password []rune, i, j int) *match {
+	return &match{kind: matchBruteforce, i: i, j: j, token: string(password[i : j+1])}
+}
+
+// guessBits estimates the bits (log2 of the guesses) it takes to guess a match on its own,
+// in a password of passwordLength characters.
+func (m *matcher) guessBits(found *match, passwordLength int) float64 {
+	if found.estimated {
+		return found.bits
+	}
+
+	length := len([]rune(found.token))
+	minGuesses := 1.0
+
+	if length < passwordLength {
+		minGuesses = minSubmatchGuessesMultiChar
+		if length == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		}
+	}
+
+	var bits float64
+
+	switch found.kind {
+	case matchDictionary:
+		bits = dictionaryBits(found)
+	case matchSpatial:
+		bits = spatialBits(found, length)
+	case matchRepeat:
+		bits = found.baseBits + math.Log2(float64(found.repeats))
+	case matchSequence:
+		bits = sequenceBits(found, length)
+	case matchDate:
+		bits = math.Log2(float64(yearSpace(found.year, m.referenceYear) * daysPerYear))
+		if found.separator != "" {
+			bits += math.Log2(dateSeparators)
+		}
+	case matchYear:
+		bits = math.Log2(float64(yearSpace(found.year, m.referenceYear)))
+	case matchBruteforce:
+		bits = float64(length) * m.bruteforceBits
+
+		bruteforceMin := minSubmatchGuessesMultiChar + 1.0
+		if length == 1 {
+			bruteforceMin = minSubmatchGuessesSingleChar + 1.0
+		}
+
+		bits = max(bits, math.Log2(bruteforceMin))
+	}
+
+	found.bits = max(bits, math.Log2(minGuesses))
+	found.estimated = true
+
+	return found.bits
+}
+
+// dictionaryBits estimates a dictionary match: its rank, times the ways to capitalize it,
+// to substitute its l33t characters and to reverse it.
+func dictionaryBits(found *match) float64 {
+	bits := math.Log2(float64(found.rank)) + uppercaseBits(found.token) + l33tBits(found)
+
+	if found.reversed {
+		bits++
+	}
+
+	return bits
+}
+
+// uppercaseBits estimates the ways to capitalize a word: none for all lowercase, one bit for
+// the common styles (first letter, last letter or all uppercase), otherwise the ways to choose
+// its uppercase letters, up to the smaller of the uppercase and lowercase counts.
+func uppercaseBits(token string) float64 {
+	runes := []rune(token)
+	upper, lower := 0, 0
+
+	for _, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+
+	switch {
+	case upper == 0:
+		return 0
+	case lower == 0,
+		upper == 1 && (unicode.IsUpper(runes[0]) || unicode.IsUpper(runes[len(runes)-1])):
+		return 1
+	default:
+		return log2Choices(upper, lower)
+	}
+}
+
+// l33tBits estimates the ways to substitute the l33t characters of a dictionary match:
+// for each substitution, the ways to choose which of the letters are substituted.
+func l33tBits(found *match) float64 {
+	bits := 0.0
+	lower := strings.ToLower(found.token)
+
+	for char, letter := range found.l33t {
+		substituted := strings.Count(lower, string(char))
+		unsubstituted := strings.Count(lower, string(letter))
+
+		if substituted == 0 || unsubstituted == 0 {
+			bits++
+
+			continue
+		}
+
+		bits += log2Choices(substituted, unsubstituted)
+	}
+
+	return bits
+}
+
+// spatialBits estimates a keyboard walk of length keys with the given turns:
+// the walks of each length up to this one, from any key, with up to as many turns in any direction.
+// Shifted keys multiply it by the ways to choose them.
+func spatialBits(found *match, length int) float64 {
+	graph := found.graph
+	guesses := 0.0
+
+	for i := 2; i <= length; i++ {
+		for j := 1; j <= min(found.turns, i-1); j++ {
+			guesses += math.Exp2(log2Binomial(i-1, j-1)) * graph.startingPositions * math.Pow(graph.averageDegree, float64(j))
+		}
+	}
+
+	bits := math.Log2(min(guesses, math.MaxFloat64))
+
+	switch shifted, unshifted := found.shifted, length-found.shifted; {
+	case shifted == 0:
+	case unshifted == 0:
+		bits++
+	default:
+		bits += log2Choices(shifted, unshifted)
+	}
+
+	return bits
+}
+
+// sequenceBits estimates a sequence: its starting character, its direction and its length.
+func sequenceBits(found *match, length int) float64 {
+	first := []rune(found.token)[0]
+
+	base := otherSequenceStart
+
+	switch {
+	case strings.ContainsRune("aAzZ019", first):
+		base = obviousSequenceStart
+	case unicode.IsDigit(first):
+		base = digitSequenceStart
+	}
+
+	if !found.ascending {
+		base *= 2
+	}
+
+	return math.Log2(float64(base * length))
+}
+
+// yearSpace is the number of years an attacker tries to reach year from the reference year.
+func yearSpace(year, referenceYear int) int {
+	return max(abs(year-referenceYear), minYearSpace)
+}
+
+// log2Choices returns log2 of the ways to pick 1 to min(a, b) of a+b positions.
+func log2Choices(a, b int) float64 {
+	total := 0.0
+
+	for i := 1; i <= min(a, b); i++ {
+		total += math.Exp2(log2Binomial(a+b, i))
+	}
+
+	return math.Log2(min(total, math.MaxFloat64))
+}
+
+// log2Sum returns log2(2^a + 2^b).
+func log2Sum(a, b float64) float64 {
+	high, low := max(a, b), min(a, b)
+
+	return high + math.Log1p(math.Exp2(low-high))/math.Ln2
+}
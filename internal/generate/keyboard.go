@@ -0,0 +1,202 @@
+package generate
+
+import (
+	"strings"
+	"sync"
+)
+
+// Keyboard layouts for spatial matching, one key per token with its unshifted and shifted character.
+// Each row of a slanted (typewriter) layout is indented one column further than the one above it.
+const (
+	qwertyLayout = "" +
+		"`~ 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+\n" +
+		"    qQ wW eE rR tT yY uU iI oO pP [{ ]} \\|\n" +
+		"     aA sS dD fF gG hH jJ kK lL ;: '\"\n" +
+		"      zZ xX cC vV bB nN mM ,< .> /?"
+
+	dvorakLayout = "" +
+		"`~ 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) [{ ]}\n" +
+		"    '\" ,< .> pP yY fF gG cC rR lL /? =+ \\|\n" +
+		"     aA oO eE uU iI dD hH tT nN sS -_\n" +
+		"      ;: qQ jJ kK xX bB mM wW vV zZ"
+
+	keypadLayout = "" +
+		"  / * -\n" +
+		"7 8 9 +\n" +
+		"4 5 6\n" +
+		"1 2 3\n" +
+		"  0 ."
+
+	macKeypadLayout = "" +
+		"  = / *\n" +
+		"7 8 9 -\n" +
+		"4 5 6 +\n" +
+		"1 2 3\n" +
+		"  0 ."
+)
+
+// keyboardGraph maps every character of a keyboard to its neighbours.
+type keyboardGraph struct {
+	name string
+	// adjacent lists the neighbouring keys of each character, "" where there is none.
+	// The position in the list is the direction, so a change of position is a turn.
+	adjacent map[rune][]string
+	// shifted reports whether the layout has shifted characters, as keyboards do and keypads don't.
+	shifted bool
+	// startingPositions and averageDegree size the space of walks on the keyboard.
+	startingPositions float64
+	averageDegree     float64
+}
+
+// keyboardGraphs are the keyboards walks are matched on, built once on first use.
+//
+//nolint:gochecknoglobals // Lazily built read-only tables
+var keyboardGraphs = sync.OnceValue(func() []*keyboardGraph {
+	return []*keyboardGraph{
+		newKeyboardGraph("qwerty", qwertyLayout, true),
+		newKeyboardGraph("dvorak", dvorakLayout, true),
+		newKeyboardGraph("keypad", keypadLayout, false),
+		newKeyboardGraph("mac_keypad", macKeypadLayout, false),
+	}
+})
+
+// newKeyboardGraph builds the graph of a layout. Keys of a slanted layout have six neighbours
+// (left, top-left, top-right, right, bottom-right, bottom-left); keys of an aligned keypad have eight.
+func newKeyboardGraph(name, layout string, slanted bool) *keyboardGraph {
+	type position struct{ x, y int }
+
+	keys := make(map[position]string)
+	lines := strings.Split(layout, "\n")
+	width := len(strings.Fields(lines[0])[0]) + 1
+
+	for y, line := range lines {
+		slant := 0
+		if slanted {
+			slant = y
+		}
+
+		for column := 0; column < len(line); {
+			if line[column] == ' ' {
+				column++
+
+				continue
+			}
+
+			key := line[column : column+width-1]
+			keys[position{(column - slant) / width, y}] = key
+			column += len(key)
+		}
+	}
+
+	neighbours := []position{{-1, 0}, {-1, -1}, {0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}}
+	if slanted {
+		neighbours = []position{{-1, 0}, {0, -1}, {1, -1}, {1, 0}, {0, 1}, {-1, 1}}
+	}
+
+	graph := &keyboardGraph{
+		name:     name,
+		adjacent: make(map[rune][]string),
+		shifted:  slanted,
+	}
+
+	degrees := 0
+
+	for at, key := range keys {
+		adjacent := make([]string, len(neighbours))
+
+		for i, offset := range neighbours {
+			adjacent[i] = keys[position{at.x + offset.x, at.y + offset.y}]
+
+			if adjacent[i] != "" {
+				degrees++
+			}
+		}
+
+		for _, char := range key {
+			graph.adjacent[char] = adjacent
+		}
+	}
+
+	// Every character of a key shares the key's neighbours
+	degrees *= width - 1
+
+	graph.startingPositions = float64(len(graph.adjacent))
+	graph.averageDegree = float64(degrees) / graph.startingPositions
+
+	return graph
+}
+
+// spatialMatches finds walks of three or more adjacent keys on any of the keyboards, such as "qwerty",
+// "zxcvbn" or "7410", counting their turns and, on keyboards, their shifted characters.
+func spatialMatches(password []rune) []*match {
+	var matches []*match
+
+	for _, graph := range keyboardGraphs() {
+		matches = append(matches, graph.walks(password)...)
+	}
+
+	return matches
+}
+
+// walks finds the walks of three or more adjacent keys on the keyboard.
+func (g *keyboardGraph) walks(password []rune) []*match {
+	var matches []*match
+
+	for i := 0; i < len(password)-1; {
+		j := i + 1
+		direction, turns, shifted := -1, 0, 0
+
+		if g.shifted && isShiftedKey(password[i]) {
+			shifted++
+		}
+
+		for ; j < len(password); j++ {
+			found := false
+
+			for next, keys := range g.adjacent[password[j-1]] {
+				position := strings.IndexRune(keys, password[j])
+				if position < 0 {
+					continue
+				}
+
+				found = true
+
+				if position == 1 {
+					shifted++
+				}
+
+				if next != direction {
+					turns++
+					direction = next
+				}
+
+				break
+			}
+
+			if !found {
+				break
+			}
+		}
+
+		if j-i > 2 { //nolint:mnd // a walk has at least three keys
+			matches = append(matches, &match{
+				kind:    matchSpatial,
+				i:       i,
+				j:       j - 1,
+				token:   string(password[i:j]),
+				graph:   g,
+				turns:   turns,
+				shifted: shifted,
+			})
+		}
+
+		i = j
+	}
+
+	return matches
+}
+
+// isShiftedKey reports whether typing r on a US keyboard takes the shift key.
+func isShiftedKey(r rune) bool {
+	return strings.ContainsRune(`~!@#$%^&*()_+QWERTYUIOP{}|ASDFGHJKL:"ZXCVBNM<>?`, r)
+}
@@ -0,0 +1,144 @@
+package generate
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// lengthClass describes the outputs of a token that have one length: their total probability
+// and their average surprisal (-log2 of each output's probability), which over all lengths
+// averages to the token's entropy.
+type lengthClass struct {
+	probability float64
+	bits        float64
+}
+
+// outputLengths returns the length classes of token's outputs, indexed by length in characters,
+// and false if the token's lengths are not known.
+func outputLengths(token Token) ([]lengthClass, bool) {
+	switch t := token.(type) {
+	case lengthToken:
+		return t.lengthClasses(), true
+	case *DigitToken:
+		return fixedLength(t.Count, t.EntropyBits()), true
+	case *SymbolToken:
+		return fixedLength(t.Count, t.EntropyBits()), true
+	case *CharsetToken:
+		return fixedLength(t.Count, t.EntropyBits()), true
+	case *SeparatorToken:
+		return fixedLength(utf8.RuneCountInString(t.Value), 0), true
+	case *LiteralToken:
+		return fixedLength(utf8.RuneCountInString(t.Value), 0), true
+	case *SequenceToken, *RepeatToken, *ChoiceToken, *OptionalToken:
+		return tokensLengths([]Token{token})
+	case *ShuffleToken:
+		separators := utf8.RuneCountInString(t.Separator) * (len(t.Tokens) - 1)
+
+		return joinedLengths(t.Tokens, fixedLength(separators, arrangementBits(t.Tokens)))
+	case *InsideToken:
+		hosts, _ := t.split()
+		separators := utf8.RuneCountInString(t.Separator) * (len(hosts) - 1)
+
+		// The positions depend on the word lengths; credit their average entropy to every length.
+		positions := t.EntropyBits()
+
+		for _, inner := range t.Tokens {
+			positions -= inner.EntropyBits()
+		}
+
+		return joinedLengths(t.Tokens, fixedLength(separators, positions))
+	default:
+		return nil, false
+	}
+}
+
+// patternLengths returns the length classes of the pattern's passphrases.
+func patternLengths(pattern *Pattern) ([]lengthClass, bool) {
+	return tokensLengths(pattern.Tokens)
+}
+
+// fixedLength returns the length classes of a token that always has the given length and entropy.
+func fixedLength(length int, bits float64) []lengthClass {
+	classes := make([]lengthClass, max(length, 0)+1)
+	classes[max(length, 0)] = lengthClass{probability: 1, bits: bits}
+
+	return classes
+}
+
+// joinedLengths returns the length classes of tokens generated one after another,
+// starting from the given classes (for separators and other fixed parts).
+func joinedLengths(tokens []Token, start []lengthClass) ([]lengthClass, bool) {
+	total := start
+
+	for _, token := range tokens {
+		classes, ok := outputLengths(token)
+		if !ok {
+			return nil, false
+		}
+
+		total = convolveLengths(total, classes)
+	}
+
+	return total, true
+}
+
+// convolveLengths combines the length classes of two independent parts:
+// lengths and surprisals add up.
+func convolveLengths(first, second []lengthClass) []lengthClass {
+	combined := make([]lengthClass, len(first)+len(second)-1)
+
+	for i, a := range first {
+		for j, b := range second {
+			p := a.probability * b.probability
+			if p == 0 {
+				continue
+			}
+
+			// Accumulate probability-weighted surprisal, normalized below.
+			combined[i+j].probability += p
+			combined[i+j].bits += p * (a.bits + b.bits)
+		}
+	}
+
+	for i := range combined {
+		if combined[i].probability > 0 {
+			combined[i].bits /= combined[i].probability
+		}
+	}
+
+	return combined
+}
+
+// constrainedEntropy returns the entropy of the outputs with a length in [minLength, maxLength]
+// (no upper bound if maxLength is zero), each kept output becoming more likely by the same factor,
+// together with the probability of such a length.
+func constrainedEntropy(classes []lengthClass, minLength, maxLength int) (float64, float64) {
+	probability, surprisal := 0.0, 0.0
+
+	for length, class := range classes {
+		if length < minLength || (maxLength > 0 && length > maxLength) {
+			continue
+		}
+
+		probability += class.probability
+		surprisal += class.probability * class.bits
+	}
+
+	if probability == 0 {
+		return 0, 0
+	}
+
+	// Each kept output x has probability P(x)/probability, so its surprisal drops by -log2(probability).
+	return surprisal/probability + math.Log2(probability), probability
+}
+
+// repeated returns count copies of token.
+func repeated(token Token, count int) []Token {
+	tokens := make([]Token, max(count, 0))
+
+	for i := range tokens {
+		tokens[i] = token
+	}
+
+	return tokens
+}
@@ -0,0 +1,681 @@
+package generate
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+)
+
+// Kinds of matches, as reported in PatternMatch.Type.
+const (
+	matchDictionary = "dictionary"
+	matchSpatial    = "spatial"
+	matchRepeat     = "repeat"
+	matchSequence   = "sequence"
+	matchDate       = "date"
+	matchYear       = "year"
+	matchBruteforce = "bruteforce"
+)
+
+const (
+	// maxSequenceDelta is the largest step between the characters of a sequence, as in "aceg" or "9630".
+	maxSequenceDelta = 5
+
+	// minDateYear and maxDateYear bound the years of dates; minDateDigits and maxDateDigits
+	// bound the digits of a date without separators, and minDateLength and maxDateLength the
+	// characters of one with separators.
+	minDateYear   = 1000
+	maxDateYear   = 2050
+	minDateDigits = 4
+	maxDateDigits = 8
+	minDateLength = 6
+	maxDateLength = 10
+
+	// minRecentYear and maxRecentYear bound the years matched on their own.
+	minRecentYear = 1900
+	maxRecentYear = 2049
+	yearDigits    = 4
+
+	// maxDay, maxMonth and maxShortYear bound the parts of a date; two-digit years above
+	// shortYearPivot are in the 1900s, the others in the 2000s.
+	maxDay         = 31
+	maxMonth       = 12
+	maxShortYear   = 99
+	shortYearPivot = 50
+
+	// maxL33tTables bounds the l33t substitution tables tried on a password.
+	maxL33tTables = 256
+)
+
+// match is a part of a password recognized by a matcher, with what it takes to guess it.
+type match struct {
+	kind  string
+	i, j  int // positions of the first and last character, in runes
+	token string
+
+	// Dictionary matches: the word as listed, its rank and list, and how the token differs from it.
+	word     string
+	rank     int
+	list     string
+	uniform  bool
+	reversed bool
+	l33t     map[rune]rune
+
+	// Spatial matches: the keyboard, the changes of direction and the shifted characters.
+	graph   *keyboardGraph
+	turns   int
+	shifted int
+
+	// Repeat matches: the repeated part, the bits to guess it and the number of repetitions.
+	base     string
+	baseBits float64
+	repeats  int
+
+	// Sequence matches: the direction.
+	ascending bool
+
+	// Date and year matches.
+	year, month, day int
+	separator        string
+
+	// bits is log2 of the guesses, once estimated.
+	bits      float64
+	estimated bool
+}
+
+// rankedDict is a word list that dictionary matches are looked up in.
+type rankedDict struct {
+	name  string
+	ranks map[string]int
+	// uniform lists have equally likely words, such as a diceware list, so every rank is the list size.
+	uniform   bool
+	maxLength int
+}
+
+// newRankedDict indexes words by rank, the most common first, or all with the list size if uniform.
+func newRankedDict(name string, words []string, uniform bool) rankedDict {
+	dict := rankedDict{
+		name:    name,
+		ranks:   make(map[string]int, len(words)),
+		uniform: uniform,
+	}
+
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if _, ok := dict.ranks[word]; ok {
+			continue
+		}
+
+		dict.ranks[word] = i + 1
+		if uniform {
+			dict.ranks[word] = len(words)
+		}
+
+		dict.maxLength = max(dict.maxLength, len([]rune(word)))
+	}
+
+	return dict
+}
+
+// frequencyDicts are the embedded frequency lists, indexed once on first use.
+//
+//nolint:gochecknoglobals // Lazily built read-only tables
+var frequencyDicts = sync.OnceValue(func() []rankedDict {
+	lists := dictionary.FrequencyLists()
+	dicts := make([]rankedDict, len(lists))
+
+	for i, list := range lists {
+		dicts[i] = newRankedDict(list.Name, list.Words, false)
+	}
+
+	return dicts
+})
+
+// matcher finds the guessable parts of a password, in the manner of zxcvbn.
+type matcher struct {
+	dicts         []rankedDict
+	referenceYear int
+	// bruteforceBits are the bits per character of the parts no pattern covers. zxcvbn counts 10 guesses
+	// per character; the password's character sets are used instead, so random strings score as such.
+	bruteforceBits float64
+}
+
+// matches returns every match found in the password by all matchers.
+func (m *matcher) matches(password []rune) []*match {
+	var matches []*match
+
+	matches = append(matches, m.dictionaryMatches(password)...)
+	matches = append(matches, m.reverseDictionaryMatches(password)...)
+	matches = append(matches, m.l33tMatches(password)...)
+	matches = append(matches, spatialMatches(password)...)
+	matches = append(matches, m.repeatMatches(password)...)
+	matches = append(matches, sequenceMatches(password)...)
+	matches = append(matches, dateMatches(password, m.referenceYear)...)
+	matches = append(matches, yearMatches(password)...)
+
+	return matches
+}
+
+// dictionaryMatches finds the words of the dictionaries in the password, regardless of case.
+func (m *matcher) dictionaryMatches(password []rune) []*match {
+	lower := make([]rune, len(password))
+
+	for i, r := range password { //nolint:varnamelen // i and r are standard for rune iteration
+		lower[i] = unicode.ToLower(r)
+	}
+
+	var matches []*match
+
+	for _, dict := range m.dicts {
+		for i := range lower {
+			for j := i; j < len(lower) && j-i < dict.maxLength; j++ {
+				word := string(lower[i : j+1])
+
+				rank, ok := dict.ranks[word]
+				if !ok {
+					continue
+				}
+
+				matches = append(matches, &match{
+					kind:    matchDictionary,
+					i:       i,
+					j:       j,
+					token:   string(password[i : j+1]),
+					word:    word,
+					rank:    rank,
+					list:    dict.name,
+					uniform: dict.uniform,
+				})
+			}
+		}
+	}
+
+	return matches
+}
+
+// reverseDictionaryMatches finds the words of the dictionaries written backwards.
+func (m *matcher) reverseDictionaryMatches(password []rune) []*match {
+	reversed := slices.Clone(password)
+	slices.Reverse(reversed)
+
+	matches := m.dictionaryMatches(reversed)
+
+	for _, found := range matches {
+		token := []rune(found.token)
+		slices.Reverse(token)
+
+		found.token = string(token)
+		found.i, found.j = len(password)-1-found.j, len(password)-1-found.i
+		found.reversed = true
+	}
+
+	return matches
+}
+
+// l33tMatches finds the words of the dictionaries with letters replaced by look-alikes, as in "p@ssw0rd".
+func (m *matcher) l33tMatches(password []rune) []*match {
+	var (
+		matches []*match
+		seen    = make(map[string]bool)
+	)
+
+	for _, table := range l33tTables(password) {
+		substituted := make([]rune, len(password))
+
+		for i, r := range password { //nolint:varnamelen // i and r are standard for rune iteration
+			substituted[i] = r
+
+			if letter, ok := table[r]; ok {
+				substituted[i] = letter
+			}
+		}
+
+		for _, found := range m.dictionaryMatches(substituted) {
+			token := password[found.i : found.j+1]
+			if len(token) < 2 || strings.ToLower(string(token)) == found.word {
+				continue
+			}
+
+			found.token = string(token)
+			found.l33t = make(map[rune]rune)
+
+			for _, r := range token {
+				if letter, ok := table[r]; ok {
+					found.l33t[r] = letter
+				}
+			}
+
+			key := fmt.Sprint(found.i, found.j, found.list, found.word)
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+
+			matches = append(matches, found)
+		}
+	}
+
+	return matches
+}
+
+// l33tTables returns the ways to read the look-alike characters of the password as letters,
+// each table mapping every look-alike in the password to one of the letters it can stand for.
+func l33tTables(password []rune) []map[rune]rune {
+	letters := map[rune]string{
+		'4': "a", '@': "a", '8': "b", '(': "c", '{': "c", '[': "c", '<': "c", '3': "e", '6': "g", '9': "g",
+		'1': "il", '!': "i", '|': "il", '7': "lt", '0': "o", '$': "s", '5': "s", '+': "t", '%': "x", '2': "z",
+	}
+
+	var present []rune
+
+	for _, r := range password {
+		if _, ok := letters[r]; ok && !slices.Contains(present, r) {
+			present = append(present, r)
+		}
+	}
+
+	if len(present) == 0 {
+		return nil
+	}
+
+	sort.Slice(present, func(a, b int) bool { return present[a] < present[b] })
+
+	tables := []map[rune]rune{{}}
+
+	for _, char := range present {
+		var next []map[rune]rune
+
+		for _, table := range tables {
+			for _, letter := range letters[char] {
+				if len(next) == maxL33tTables {
+					break
+				}
+
+				extended := make(map[rune]rune, len(table)+1)
+				for from, to := range table {
+					extended[from] = to
+				}
+
+				extended[char] = letter
+				next = append(next, extended)
+			}
+		}
+
+		tables = next
+	}
+
+	return tables
+}
+
+// repeatMatches finds parts repeated back to back, as in "aaaa" or "abcabc".
+// At each position the repetition covering the most characters wins, with the shortest repeated part.
+func (m *matcher) repeatMatches(password []rune) []*match {
+	var matches []*match
+
+	for i := 0; i < len(password); {
+		length, repeats := longestRepeat(password[i:])
+		if repeats < 2 { //nolint:mnd // a repetition needs two copies
+			i++
+
+			continue
+		}
+
+		end := i + length*repeats
+		base := password[i : i+length]
+
+		matches = append(matches, &match{
+			kind:     matchRepeat,
+			i:        i,
+			j:        end - 1,
+			token:    string(password[i:end]),
+			base:     string(base),
+			baseBits: m.estimate(base).bits,
+			repeats:  repeats,
+		})
+
+		i = end
+	}
+
+	return matches
+}
+
+// longestRepeat returns the length and count of the repetition of a prefix of runes that covers
+// the most characters.
+func longestRepeat(runes []rune) (int, int) {
+	bestLength, bestRepeats := 0, 0
+
+	for length := 1; 2*length <= len(runes); length++ {
+		repeats := 1
+
+		for (repeats+1)*length <= len(runes) &&
+			slices.Equal(runes[repeats*length:(repeats+1)*length], runes[:length]) {
+			repeats++
+		}
+
+		if repeats >= 2 && length*repeats > bestLength*bestRepeats {
+			bestLength, bestRepeats = length, repeats
+		}
+	}
+
+	return bestLength, bestRepeats
+}
+
+// sequenceMatches finds runs of characters with a constant step of at most maxSequenceDelta,
+// in either direction, as in "abcd", "9753" or "ZYX". Runs of two only count with a step of one.
+func sequenceMatches(password []rune) []*match {
+	if len(password) < 2 { //nolint:mnd // a sequence needs two characters
+		return nil
+	}
+
+	var matches []*match
+
+	add := func(i, j, delta int) {
+		long := j-i > 1 || delta == 1 || delta == -1
+		if long && delta != 0 && abs(delta) <= maxSequenceDelta {
+			matches = append(matches, &match{
+				kind:      matchSequence,
+				i:         i,
+				j:         j,
+				token:     string(password[i : j+1]),
+				ascending: delta > 0,
+			})
+		}
+	}
+
+	start, last := 0, int(password[1]-password[0])
+
+	for k := 1; k < len(password); k++ {
+		delta := int(password[k] - password[k-1])
+		if delta == last {
+			continue
+		}
+
+		add(start, k-1, last)
+
+		start, last = k-1, delta
+	}
+
+	add(start, len(password)-1, last)
+
+	return matches
+}
+
+// dateMatches finds dates of 4 to 8 digits, such as "1998" (1/9/98) or "20240315", and dates with
+// a separator, such as "4/1/98" or "2024-03-15". Of the readings of a date, the one closest to
+// the reference year wins. Dates inside other dates are dropped.
+func dateMatches(password []rune, referenceYear int) []*match {
+	var matches []*match
+
+	closer := func(a, b date) bool {
+		return abs(a.year-referenceYear) < abs(b.year-referenceYear)
+	}
+
+	for i := range password {
+		for j := i + minDateDigits - 1; j < len(password) && j-i < maxDateDigits; j++ {
+			token := string(password[i : j+1])
+			if !isDigits(token) {
+				break
+			}
+
+			var (
+				best  date
+				found bool
+			)
+
+			for _, split := range dateSplits(len(token)) {
+				candidate, ok := toDate(atoi(token[:split[0]]), atoi(token[split[0]:split[1]]), atoi(token[split[1]:]))
+				if ok && (!found || closer(candidate, best)) {
+					best, found = candidate, true
+				}
+			}
+
+			if found {
+				matches = append(matches, best.match(i, j, token, ""))
+			}
+		}
+	}
+
+	separated := regexp.MustCompile(`^(\d{1,4})([\s/\\_.-])(\d{1,2})([\s/\\_.-])(\d{1,4})$`)
+
+	for i := range password {
+		for j := i + minDateLength - 1; j < len(password) && j-i < maxDateLength; j++ {
+			token := string(password[i : j+1])
+
+			parts := separated.FindStringSubmatch(token)
+			if parts == nil || parts[2] != parts[4] {
+				continue
+			}
+
+			if found, ok := toDate(atoi(parts[1]), atoi(parts[3]), atoi(parts[5])); ok {
+				matches = append(matches, found.match(i, j, token, parts[2]))
+			}
+		}
+	}
+
+	return slices.DeleteFunc(matches, func(inner *match) bool {
+		return slices.ContainsFunc(matches, func(outer *match) bool {
+			return outer != inner && outer.i <= inner.i && outer.j >= inner.j
+		})
+	})
+}
+
+// yearMatches finds recent years, from minRecentYear to maxRecentYear.
+func yearMatches(password []rune) []*match {
+	var matches []*match
+
+	for i := 0; i+yearDigits <= len(password); i++ {
+		token := string(password[i : i+yearDigits])
+		if !isDigits(token) {
+			continue
+		}
+
+		if year := atoi(token); year >= minRecentYear && year <= maxRecentYear {
+			matches = append(matches, &match{kind: matchYear, i: i, j: i + yearDigits - 1, token: token, year: year})
+		}
+	}
+
+	return matches
+}
+
+// date is a day, month and year read from digits.
+type date struct {
+	year, month, day int
+}
+
+// match returns the date as a match of the token at positions i to j.
+func (d date) match(i, j int, token, separator string) *match {
+	return &match{
+		kind:      matchDate,
+		i:         i,
+		j:         j,
+		token:     token,
+		year:      d.year,
+		month:     d.month,
+		day:       d.day,
+		separator: separator,
+	}
+}
+
+// dateSplits returns where to split length digits into three numbers, as the ends of the first two.
+//
+//nolint:mnd // The splits of 4 to 8 digits
+func dateSplits(length int) [][2]int {
+	switch length {
+	case 4: // 1 1 2, 2 1 1
+		return [][2]int{{1, 2}, {2, 3}}
+	case 5: // 1 2 2, 2 1 2
+		return [][2]int{{1, 3}, {2, 3}}
+	case 6: // 1 1 4, 2 2 2, 4 1 1
+		return [][2]int{{1, 2}, {2, 4}, {4, 5}}
+	case 7: // 1 2 4, 2 1 4, 4 1 2, 4 2 1
+		return [][2]int{{1, 3}, {2, 3}, {4, 5}, {4, 6}}
+	case 8: // 2 2 4, 4 2 2
+		return [][2]int{{2, 4}, {4, 6}}
+	default:
+		return nil
+	}
+}
+
+// toDate reads three numbers as a day, month and year in any common order.
+// A year comes first or last; two-digit years are read as 1951 to 2050.
+// After a year the month comes first, as in "1987-05-12", and before one the day, as in "12/05/1987".
+func toDate(first, second, third int) (date, bool) {
+	ints := []int{first, second, third}
+
+	if second > maxDay || second <= 0 {
+		return date{}, false
+	}
+
+	over12, over31, under1 := 0, 0, 0
+
+	for _, value := range ints {
+		if (value > maxShortYear && value < minDateYear) || value > maxDateYear {
+			return date{}, false
+		}
+
+		if value > maxDay {
+			over31++
+		}
+
+		if value > maxMonth {
+			over12++
+		}
+
+		if value <= 0 {
+			under1++
+		}
+	}
+
+	if over31 >= 2 || over12 == len(ints) || under1 >= 2 {
+		return date{}, false
+	}
+
+	splits := []struct {
+		year int
+		rest [2]int
+	}{
+		{third, [2]int{first, second}},
+		{first, [2]int{third, second}},
+	}
+
+	for _, split := range splits {
+		if split.year >= minDateYear && split.year <= maxDateYear {
+			day, month, ok := toDayMonth(split.rest)
+
+			return date{year: split.year, month: month, day: day}, ok
+		}
+	}
+
+	for _, split := range splits {
+		if day, month, ok := toDayMonth(split.rest); ok {
+			return date{year: fourDigitYear(split.year), month: month, day: day}, true
+		}
+	}
+
+	return date{}, false
+}
+
+// toDayMonth reads two numbers as a day and a month, in either order.
+func toDayMonth(ints [2]int) (int, int, bool) {
+	for _, pair := range [][2]int{ints, {ints[1], ints[0]}} {
+		if pair[0] >= 1 && pair[0] <= maxDay && pair[1] >= 1 && pair[1] <= maxMonth {
+			return pair[0], pair[1], true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// fourDigitYear expands a two-digit year to 1951 to 2050.
+func fourDigitYear(year int) int {
+	switch {
+	case year > maxShortYear:
+		return year
+	case year > shortYearPivot:
+		return year + 1900 //nolint:mnd // 51 to 99 are the 1900s
+	default:
+		return year + 2000 //nolint:mnd // 00 to 50 are the 2000s
+	}
+}
+
+// isDigits reports whether str is made of ASCII digits only.
+func isDigits(str string) bool {
+	for _, r := range str {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return str != ""
+}
+
+// atoi converts ASCII digits to an integer.
+func atoi(str string) int {
+	value, _ := strconv.Atoi(str)
+
+	return value
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// describe explains the match for PatternMatch.Description.
+func (found *match) describe() string {
+	switch found.kind {
+	case matchDictionary:
+		description := fmt.Sprintf("Word %q from %s (rank %d)", found.word, found.list, found.rank)
+		if found.uniform {
+			description = fmt.Sprintf("Word %q from %s (%d words)", found.word, found.list, found.rank)
+		}
+
+		if found.reversed {
+			description += ", reversed"
+		}
+
+		if len(found.l33t) > 0 {
+			substitutions := make([]string, 0, len(found.l33t))
+
+			for char, letter := range found.l33t {
+				substitutions = append(substitutions, string(char)+"->"+string(letter))
+			}
+
+			slices.Sort(substitutions)
+
+			description += ", l33t " + strings.Join(substitutions, " ")
+		}
+
+		return description
+	case matchSpatial:
+		return fmt.Sprintf("Keyboard walk on %s: %q (%d turns, %d shifted)",
+			found.graph.name, found.token, found.turns, found.shifted)
+	case matchRepeat:
+		return fmt.Sprintf("Repeated %q %d times", found.base, found.repeats)
+	case matchSequence:
+		direction := "Descending"
+		if found.ascending {
+			direction = "Ascending"
+		}
+
+		return fmt.Sprintf("%s sequence: %q", direction, found.token)
+	case matchDate:
+		return fmt.Sprintf("Date %04d-%02d-%02d: %q", found.year, found.month, found.day, found.token)
+	case matchYear:
+		return fmt.Sprintf("Recent year: %q", found.token)
+	default:
+		return fmt.Sprintf("Brute force: %q", found.token)
+	}
+}
@@ -0,0 +1,57 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToDate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                 string
+		first, second, third int
+		want                 date
+		ok                   bool
+	}{
+		{name: "year first", first: 1987, second: 5, third: 12, want: date{1987, 5, 12}, ok: true},
+		{name: "year first, day before month", first: 1987, second: 13, third: 5, want: date{1987, 5, 13}, ok: true},
+		{name: "year last", first: 12, second: 5, third: 1987, want: date{1987, 5, 12}, ok: true},
+		{name: "year last, month before day", first: 5, second: 13, third: 1987, want: date{1987, 5, 13}, ok: true},
+		{name: "two-digit year first", first: 87, second: 5, third: 12, want: date{1987, 5, 12}, ok: true},
+		{name: "two-digit year last", first: 12, second: 5, third: 87, want: date{1987, 5, 12}, ok: true},
+		{name: "no valid month", first: 1987, second: 13, third: 13},
+		{name: "no year", first: 40, second: 5, third: 40},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := toDate(test.first, test.second, test.third)
+			if ok != test.ok || (ok && got != test.want) {
+				t.Errorf("toDate(%d, %d, %d) = %+v, %v, want %+v, %v",
+					test.first, test.second, test.third, got, ok, test.want, test.ok)
+			}
+		})
+	}
+}
+
+func TestEstimateLongPassword(t *testing.T) {
+	t.Parallel()
+
+	m := &matcher{dicts: frequencyDicts(), referenceYear: 2024, bruteforceBits: 5}
+
+	// Only the first maxMatchedLength characters are matched, the rest is brute force.
+	password := []rune(strings.Repeat("password", 250))
+	matched := m.estimate(password[:maxMatchedLength])
+
+	got := m.estimate(password)
+	if want := matched.bits + float64(len(password)-maxMatchedLength)*5; got.bits != want {
+		t.Errorf("estimate() = %v bits, want %v", got.bits, want)
+	}
+
+	if last := got.sequence[len(got.sequence)-1]; last.kind != matchBruteforce || last.i != maxMatchedLength {
+		t.Errorf("estimate() ends with %+v, want brute force from %d", last, maxMatchedLength)
+	}
+}
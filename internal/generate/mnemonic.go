@@ -0,0 +1,182 @@
+package generate
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+	"github.com/idelchi/pwgen/internal/safety"
+)
+
+const (
+	// DefaultMnemonicBits is the entropy of a 12-word BIP39 mnemonic.
+	DefaultMnemonicBits = 128
+
+	// minMnemonicBits and maxMnemonicBits bound the entropy of a BIP39 mnemonic,
+	// which must be a multiple of mnemonicBitsStep.
+	minMnemonicBits  = 128
+	maxMnemonicBits  = 256
+	mnemonicBitsStep = 32
+	// mnemonicWordBits is the number of bits each word encodes (2^11 = 2048 words).
+	mnemonicWordBits = 11
+	// bitsPerByte is the number of bits in a byte.
+	bitsPerByte = 8
+)
+
+// MnemonicToken generates a BIP39 mnemonic from the English wordlist, as in "BIP39{128}".
+//
+// Bits of entropy are followed by a checksum of Bits/32 bits, the start of their SHA-256 hash,
+// and the result is split into 11-bit indices into the wordlist, giving (Bits + Bits/32) / 11 words
+// joined by spaces. The checksum is determined by the entropy, so the last word adds fewer bits
+// than the others and the mnemonic's entropy is exactly Bits.
+type MnemonicToken struct {
+	Bits int
+}
+
+// Generate produces a mnemonic for Bits of entropy read from source.
+func (t *MnemonicToken) Generate(source io.Reader) (string, error) {
+	if err := validateMnemonicBits(t.Bits); err != nil {
+		return "", err
+	}
+
+	if source == nil {
+		return "", errors.New("no source of randomness")
+	}
+
+	entropy := make([]byte, t.Bits/bitsPerByte)
+	defer safety.WipeBytes(entropy)
+
+	if _, err := io.ReadFull(source, entropy); err != nil {
+		return "", fmt.Errorf("reading random bytes: %w", err)
+	}
+
+	return EncodeMnemonic(entropy)
+}
+
+// EntropyBits returns the bits of entropy; the checksum adds none.
+func (t *MnemonicToken) EntropyBits() float64 {
+	return float64(t.Bits)
+}
+
+// Type returns a description of this token type.
+func (t *MnemonicToken) Type() string {
+	return fmt.Sprintf("bip39(%d)", t.Bits)
+}
+
+// DSL returns the mnemonic in pattern DSL syntax, e.g. "BIP39{128}".
+func (t *MnemonicToken) DSL() string {
+	return fmt.Sprintf("BIP39{%d}", t.Bits)
+}
+
+// EncodeMnemonic returns the BIP39 mnemonic of 16 to 32 bytes of entropy (a multiple of 4).
+func EncodeMnemonic(entropy []byte) (string, error) {
+	bits := len(entropy) * bitsPerByte
+	if err := validateMnemonicBits(bits); err != nil {
+		return "", err
+	}
+
+	checksumBits := bits / mnemonicBitsStep
+	hash := sha256.Sum256(entropy)
+
+	// entropy || checksum, read as a big-endian integer
+	value := new(big.Int).SetBytes(entropy)
+	value.Lsh(value, uint(checksumBits))                                    //nolint:gosec // at most 8
+	value.Or(value, big.NewInt(int64(hash[0]>>(bitsPerByte-checksumBits)))) //nolint:gosec // at most 8
+
+	words := dictionary.BIP39English().Words()
+	count := (bits + checksumBits) / mnemonicWordBits
+	mnemonic := make([]string, count)
+	mask := big.NewInt(1<<mnemonicWordBits - 1)
+	index := new(big.Int)
+
+	for i := count - 1; i >= 0; i-- {
+		index.And(value, mask)
+		mnemonic[i] = words[index.Int64()]
+		value.Rsh(value, mnemonicWordBits)
+	}
+
+	return strings.Join(mnemonic, " "), nil
+}
+
+// MnemonicCheck is the result of validating a passphrase as a BIP39 mnemonic.
+type MnemonicCheck struct {
+	Valid       bool   `json:"valid"`
+	Words       int    `json:"words"`
+	EntropyBits int    `json:"entropyBits,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// CheckMnemonic validates a BIP39 mnemonic in the English wordlist: its word count, its words
+// and its checksum. Words are separated by whitespace and compared case-insensitively.
+func CheckMnemonic(mnemonic string) MnemonicCheck {
+	fields := strings.Fields(strings.ToLower(mnemonic))
+	check := MnemonicCheck{Words: len(fields)}
+
+	bits, err := decodeMnemonic(fields)
+	if err != nil {
+		check.Error = err.Error()
+
+		return check
+	}
+
+	check.Valid = true
+	check.EntropyBits = bits
+
+	return check
+}
+
+// decodeMnemonic verifies the words of a mnemonic and returns the bits of entropy they encode.
+func decodeMnemonic(fields []string) (int, error) {
+	total := len(fields) * mnemonicWordBits
+	// Entropy and checksum make 33 parts, the checksum being one of them.
+	checksumBits := total / (mnemonicBitsStep + 1)
+	bits := total - checksumBits
+
+	if validateMnemonicBits(bits) != nil || total%(mnemonicBitsStep+1) != 0 {
+		return 0, fmt.Errorf("a mnemonic has 12, 15, 18, 21 or 24 words, not %d", len(fields))
+	}
+
+	positions := make(map[string]int, 1<<mnemonicWordBits)
+
+	for i, word := range dictionary.BIP39English().Words() {
+		positions[word] = i
+	}
+
+	value := new(big.Int)
+
+	for i, word := range fields {
+		index, ok := positions[word]
+		if !ok {
+			return 0, fmt.Errorf("word %d (%q) is not in the BIP39 English wordlist", i+1, word)
+		}
+
+		value.Lsh(value, mnemonicWordBits)
+		value.Or(value, big.NewInt(int64(index)))
+	}
+
+	checksum := new(big.Int).And(value, big.NewInt(1<<checksumBits-1)).Int64()
+
+	entropy := value.Rsh(value, uint(checksumBits)).FillBytes(make([]byte, bits/bitsPerByte)) //nolint:gosec // at most 8
+	defer safety.WipeBytes(entropy)
+
+	hash := sha256.Sum256(entropy)
+
+	if int64(hash[0]>>(bitsPerByte-checksumBits)) != checksum {
+		return 0, errors.New("checksum mismatch: the last word does not match the others")
+	}
+
+	return bits, nil
+}
+
+// validateMnemonicBits checks that bits is a valid BIP39 entropy size.
+func validateMnemonicBits(bits int) error {
+	if bits < minMnemonicBits || bits > maxMnemonicBits || bits%mnemonicBitsStep != 0 {
+		return fmt.Errorf("mnemonic entropy must be 128, 160, 192, 224 or 256 bits, not %d", bits)
+	}
+
+	return nil
+}
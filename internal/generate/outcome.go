@@ -0,0 +1,758 @@
+package generate
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+const (
+	// maxVariants bounds the number of variants a pattern is resolved into. Patterns with more,
+	// such as "(D?){500}", fall back to the conservative estimate of fallbackEntropy.
+	maxVariants = 256
+	// maxEnumeratedOutputs bounds the outputs listed to compute the exact entropy of overlapping variants
+	// made of single characters, such as those of "C|D".
+	maxEnumeratedOutputs = 1 << 16
+)
+
+// variant is one way the choices and optional elements of a pattern can resolve:
+// the elements then generated one after another, and the probability of resolving that way.
+//
+// Different resolutions can produce the same output: both ways of "D? D?" that keep one digit
+// yield the same ten strings, and "D|D" yields the same digits whichever alternative is picked.
+// Entropies are therefore computed over distinct variants rather than summed over tokens.
+type variant struct {
+	elements    []element
+	probability float64
+}
+
+// element is a token without choices or optional parts. Digit, symbol, character and literal
+// tokens are split into one element per character, so "DD", "D{2}" and "D D" resolve alike.
+type element struct {
+	// key identifies the element's output distribution.
+	key   string
+	token Token
+	bits  float64
+	// alphabet holds the characters of a single-character element, empty for other elements.
+	alphabet string
+}
+
+// bits returns the entropy of the variant's elements.
+func (v variant) bits() float64 {
+	total := 0.0
+
+	for _, e := range v.elements {
+		total += e.bits
+	}
+
+	return total
+}
+
+// key identifies the variant's output distribution.
+func (v variant) key() string {
+	keys := make([]string, 0, len(v.elements))
+
+	for _, e := range v.elements {
+		keys = append(keys, e.key)
+	}
+
+	return strings.Join(keys, "\x00")
+}
+
+// tokensEntropy returns the entropy of the output of tokens generated one after another.
+//
+// Variants that can produce the same output are grouped, and the entropy is that of the group
+// plus the entropy of its outputs. The latter is exact for groups of one variant and for groups
+// of single characters with few outputs, as in "C|D". For other groups, such as "W|W@nouns",
+// it is the average entropy of the variants, a lower bound, so overlapping alternatives never
+// overstate the entropy.
+func tokensEntropy(tokens []Token) float64 {
+	resolved, ok := variants(tokens)
+	if !ok {
+		total := 0.0
+
+		for _, token := range tokens {
+			total += fallbackEntropy(token)
+		}
+
+		return total
+	}
+
+	extra := groupSurprisals(resolved)
+	total := 0.0
+
+	for i, v := range resolved {
+		total += v.probability * (v.bits() + extra[i])
+	}
+
+	return total
+}
+
+// tokensLengths returns the length classes of the output of tokens generated one after another,
+// crediting the choices the same way as tokensEntropy.
+func tokensLengths(tokens []Token) ([]lengthClass, bool) {
+	resolved, ok := variants(tokens)
+	if !ok {
+		total := fixedLength(0, 0)
+
+		for _, token := range tokens {
+			classes, ok := fallbackLengths(token)
+			if !ok {
+				return nil, false
+			}
+
+			total = convolveLengths(total, classes)
+		}
+
+		return total, true
+	}
+
+	extra := groupSurprisals(resolved)
+
+	var mixed []lengthClass
+
+	for i, v := range resolved {
+		classes := fixedLength(0, 0)
+
+		for _, e := range v.elements {
+			inner, ok := e.lengths()
+			if !ok {
+				return nil, false
+			}
+
+			classes = convolveLengths(classes, inner)
+		}
+
+		mixed = addLengths(mixed, classes, v.probability, extra[i])
+	}
+
+	return normalizeLengths(mixed), true
+}
+
+// variants resolves tokens generated one after another into their distinct variants,
+// or returns false if there are more than maxVariants.
+func variants(tokens []Token) ([]variant, bool) {
+	resolved := []variant{{probability: 1}}
+
+	for _, token := range tokens {
+		next, ok := tokenVariants(token)
+		if !ok {
+			return nil, false
+		}
+
+		if resolved, ok = joinVariants(resolved, next); !ok {
+			return nil, false
+		}
+	}
+
+	return resolved, true
+}
+
+// tokenVariants resolves a single token into its distinct variants.
+func tokenVariants(token Token) ([]variant, bool) {
+	switch t := token.(type) {
+	case *SequenceToken:
+		return variants(t.Tokens)
+	case *RepeatToken:
+		inner, ok := tokenVariants(t.Token)
+		if !ok {
+			return nil, false
+		}
+
+		resolved := []variant{{probability: 1}}
+
+		for range t.Count {
+			if resolved, ok = joinVariants(resolved, inner); !ok {
+				return nil, false
+			}
+		}
+
+		return resolved, true
+	case *ChoiceToken:
+		return choiceVariants(t.Options)
+	case *OptionalToken:
+		return choiceVariants([]Token{t.Token, &LiteralToken{}})
+	default:
+		return []variant{{elements: tokenElements(token), probability: 1}}, true
+	}
+}
+
+// choiceVariants resolves a choice between options, picked as in weightedChoice.
+func choiceVariants(options []Token) ([]variant, bool) {
+	probabilities := choiceProbabilities(options)
+
+	var resolved []variant
+
+	for i, option := range options {
+		inner, ok := tokenVariants(option)
+		if !ok {
+			return nil, false
+		}
+
+		for _, v := range inner {
+			resolved = append(resolved, variant{elements: v.elements, probability: v.probability * probabilities[i]})
+		}
+	}
+
+	return mergeVariants(resolved)
+}
+
+// joinVariants returns the variants of generating first and then second.
+func joinVariants(first, second []variant) ([]variant, bool) {
+	if len(first)*len(second) > maxVariants*maxVariants {
+		return nil, false
+	}
+
+	joined := make([]variant, 0, len(first)*len(second))
+
+	for _, a := range first {
+		for _, b := range second {
+			joined = append(joined, variant{
+				elements:    append(slices.Clip(a.elements), b.elements...),
+				probability: a.probability * b.probability,
+			})
+		}
+	}
+
+	// Distinct variants followed by the same elements stay distinct.
+	if len(second) == 1 {
+		return joined, len(joined) <= maxVariants
+	}
+
+	return mergeVariants(joined)
+}
+
+// mergeVariants adds up the probabilities of variants with the same elements.
+func mergeVariants(resolved []variant) ([]variant, bool) {
+	merged := make([]variant, 0, len(resolved))
+	index := make(map[string]int, len(resolved))
+
+	for _, v := range resolved {
+		key := v.key()
+
+		if i, ok := index[key]; ok {
+			merged[i].probability += v.probability
+
+			continue
+		}
+
+		index[key] = len(merged)
+		merged = append(merged, v)
+	}
+
+	return merged, len(merged) <= maxVariants
+}
+
+// tokenElements splits a token without choices into its elements.
+func tokenElements(token Token) []element {
+	switch t := token.(type) {
+	case *DigitToken:
+		return charElements("0123456789", t.Count, t.EntropyBits())
+	case *SymbolToken:
+		charset := t.Charset
+		if charset == "" {
+			charset = DefaultSymbolCharset
+		}
+
+		return charElements(charset, t.Count, t.EntropyBits())
+	case *CharsetToken:
+		return charElements(t.Charset, t.Count, t.EntropyBits())
+	case *LiteralToken:
+		return literalElements(t.Value)
+	case *SeparatorToken:
+		return literalElements(t.Value)
+	default:
+		return []element{{key: token.DSL(), token: token, bits: token.EntropyBits()}}
+	}
+}
+
+// charElements returns count elements of one character from charset, together carrying bits.
+func charElements(charset string, count int, bits float64) []element {
+	if count <= 0 {
+		return nil
+	}
+
+	chars := []rune(charset)
+	slices.Sort(chars)
+	alphabet := string(slices.Compact(chars))
+
+	share := bits / float64(count)
+	each := element{key: fmt.Sprintf("[%s]%g", alphabet, share), bits: share, alphabet: alphabet}
+
+	return slices.Repeat([]element{each}, count)
+}
+
+// literalElements returns the characters of a fixed value as elements.
+func literalElements(value string) []element {
+	elements := make([]element, 0, len(value))
+
+	for _, r := range value {
+		elements = append(elements, element{key: fmt.Sprintf("[%c]0", r), alphabet: string(r)})
+	}
+
+	return elements
+}
+
+// lengths returns the length classes of the element.
+func (e element) lengths() ([]lengthClass, bool) {
+	if e.alphabet != "" {
+		return fixedLength(1, e.bits), true
+	}
+
+	return outputLengths(e.token)
+}
+
+// runes returns the characters the element can produce, and false if they are not known.
+func (e element) runes() (map[rune]bool, bool) {
+	if e.alphabet != "" {
+		return runeSet(e.alphabet), true
+	}
+
+	switch t := e.token.(type) {
+	case *WordToken:
+		return t.runes(), true
+	case *SyllableToken:
+		return casedRunes(syllables(), language.Und), true
+	default:
+		return nil, false
+	}
+}
+
+// mayBeEmpty reports whether the element can produce an empty string.
+func (e element) mayBeEmpty() bool {
+	if e.alphabet != "" {
+		return false
+	}
+
+	classes, ok := outputLengths(e.token)
+
+	return !ok || len(classes) == 0 || classes[0].probability > 0
+}
+
+// groupSurprisals returns the bits the outputs of each variant carry beyond those of its elements.
+// Telling the variant's overlap group apart from the others adds -log2 of the group's probability.
+// Where the exact entropy of a group's outputs is known, what it exceeds the average entropy of
+// the group's variants is added as well, so that the bits add up to the exact entropy.
+func groupSurprisals(resolved []variant) []float64 {
+	group, probabilities := overlapGroups(resolved)
+	members := make([][]variant, len(probabilities))
+
+	for i, v := range resolved {
+		members[group[i]] = append(members[group[i]], v)
+	}
+
+	excess := make([]float64, len(probabilities))
+
+	for g, inner := range members {
+		if len(inner) < 2 { //nolint:mnd // a single variant's entropy is exact
+			continue
+		}
+
+		exact, ok := charsEntropy(inner, probabilities[g])
+		if !ok {
+			continue
+		}
+
+		average := 0.0
+
+		for _, v := range inner {
+			average += v.probability / probabilities[g] * v.bits()
+		}
+
+		excess[g] = max(exact-average, 0)
+	}
+
+	extra := make([]float64, len(resolved))
+
+	for i := range resolved {
+		extra[i] = -math.Log2(probabilities[group[i]]) + excess[group[i]]
+	}
+
+	return extra
+}
+
+// charsEntropy returns the entropy of the outputs of variants made of single characters,
+// which together have the given probability, by listing the outputs. It returns false
+// for other variants and if there are more than maxEnumeratedOutputs outputs.
+func charsEntropy(resolved []variant, probability float64) (float64, bool) {
+	count := 0
+
+	for _, v := range resolved {
+		if !allChars(v.elements) {
+			return 0, false
+		}
+
+		outputs := 1
+
+		for _, e := range v.elements {
+			if outputs *= utf8.RuneCountInString(e.alphabet); outputs > maxEnumeratedOutputs {
+				return 0, false
+			}
+		}
+
+		if count += outputs; count > maxEnumeratedOutputs {
+			return 0, false
+		}
+	}
+
+	probabilities := make(map[string]float64, count)
+
+	for _, v := range resolved {
+		outputs := map[string]float64{"": v.probability / probability}
+
+		for _, e := range v.elements {
+			chars := []rune(e.alphabet)
+			next := make(map[string]float64, len(outputs)*len(chars))
+
+			for prefix, p := range outputs {
+				for _, r := range chars {
+					next[prefix+string(r)] = p / float64(len(chars))
+				}
+			}
+
+			outputs = next
+		}
+
+		for output, p := range outputs {
+			probabilities[output] += p
+		}
+	}
+
+	entropy := 0.0
+
+	for _, p := range probabilities {
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy, true
+}
+
+// overlapGroups groups the variants that may produce the same output, directly or through
+// other variants. It returns the group of each variant, numbered from zero, and the probability of each group.
+func overlapGroups(resolved []variant) ([]int, []float64) {
+	parent := make([]int, len(resolved))
+
+	for i := range parent {
+		parent[i] = i
+	}
+
+	find := func(i int) int {
+		for parent[i] != i {
+			i = parent[i]
+		}
+
+		return i
+	}
+
+	for i := range resolved {
+		for j := i + 1; j < len(resolved); j++ {
+			if find(i) != find(j) && !disjointVariants(resolved[i].elements, resolved[j].elements) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	group := make([]int, len(resolved))
+	numbers := make(map[int]int)
+
+	var probabilities []float64
+
+	for i, v := range resolved {
+		root := find(i)
+		if _, ok := numbers[root]; !ok {
+			numbers[root] = len(probabilities)
+			probabilities = append(probabilities, 0)
+		}
+
+		group[i] = numbers[root]
+		probabilities[group[i]] += v.probability
+	}
+
+	return group, probabilities
+}
+
+// disjointVariants reports whether two distinct variants are known to never produce the same output.
+// As when summing the entropies of a sequence, elements are assumed to be told apart at their boundaries.
+func disjointVariants(a, b []element) bool {
+	switch {
+	case len(a) == 0:
+		return !allMayBeEmpty(b)
+	case len(b) == 0:
+		return !allMayBeEmpty(a)
+	}
+
+	// Elements that match up one by one, with a character from disjoint alphabets in one place.
+	if len(a) == len(b) {
+		matching, differing := true, false
+
+		for i := range a {
+			switch {
+			case a[i].key == b[i].key:
+			case a[i].alphabet != "" && b[i].alphabet != "" && !shareRunes(runeSet(a[i].alphabet), runeSet(b[i].alphabet)):
+				differing = true
+			case disjointWords(a[i], b[i]):
+				differing = true
+			default:
+				matching = false
+			}
+		}
+
+		if matching && differing {
+			return true
+		}
+	}
+
+	// Strings of single characters of different lengths.
+	if allChars(a) && allChars(b) && len(a) != len(b) {
+		return true
+	}
+
+	// Outputs built from disjoint alphabets.
+	runesA, okA := variantRunes(a)
+	runesB, okB := variantRunes(b)
+
+	return okA && okB && !shareRunes(runesA, runesB)
+}
+
+// disjointWords reports whether two word elements never produce the same word, as "W:title" and "W:lower".
+func disjointWords(a, b element) bool {
+	wordA, okA := a.token.(*WordToken)
+	wordB, okB := b.token.(*WordToken)
+
+	if !okA || !okB {
+		return false
+	}
+
+	outputsA, outputsB := wordA.outputs(), wordB.outputs()
+	if outputsA == nil || outputsB == nil {
+		return false
+	}
+
+	if len(outputsA) > len(outputsB) {
+		outputsA, outputsB = outputsB, outputsA
+	}
+
+	for output := range outputsA {
+		if outputsB[output] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// allMayBeEmpty reports whether all elements can produce an empty string.
+func allMayBeEmpty(elements []element) bool {
+	for _, e := range elements {
+		if !e.mayBeEmpty() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// allChars reports whether all elements are single characters.
+func allChars(elements []element) bool {
+	for _, e := range elements {
+		if e.alphabet == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// variantRunes returns the characters the elements can produce, and false if they are not known.
+func variantRunes(elements []element) (map[rune]bool, bool) {
+	all := make(map[rune]bool)
+
+	for _, e := range elements {
+		set, ok := e.runes()
+		if !ok {
+			return nil, false
+		}
+
+		for r := range set {
+			all[r] = true
+		}
+	}
+
+	return all, true
+}
+
+// runeSet returns the characters of s.
+func runeSet(s string) map[rune]bool {
+	set := make(map[rune]bool)
+
+	for _, r := range s {
+		set[r] = true
+	}
+
+	return set
+}
+
+// shareRunes reports whether two character sets have a character in common.
+func shareRunes(a, b map[rune]bool) bool {
+	for r := range a {
+		if b[r] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// casedRunes returns the characters of words in any of the casing styles.
+func casedRunes(words []string, lang language.Tag) map[rune]bool {
+	upper, lower := cases.Upper(lang), cases.Lower(lang)
+	set := make(map[rune]bool)
+
+	for _, word := range words {
+		for _, form := range []string{word, upper.String(word), lower.String(word)} {
+			for _, r := range form {
+				set[r] = true
+				set[unicode.ToUpper(r)] = true
+				set[unicode.ToLower(r)] = true
+				set[unicode.ToTitle(r)] = true
+			}
+		}
+	}
+
+	return set
+}
+
+// choiceProbabilities returns the probability of picking each option, proportional to
+// 2^entropy as in weightedChoice.
+func choiceProbabilities(options []Token) []float64 {
+	bits := make([]float64, len(options))
+
+	for i, option := range options {
+		bits[i] = option.EntropyBits()
+	}
+
+	total := sumOutcomeBits(bits)
+	probabilities := make([]float64, len(bits))
+
+	for i, b := range bits {
+		probabilities[i] = math.Exp2(b - total)
+	}
+
+	return probabilities
+}
+
+// fallbackEntropy returns a lower bound of the entropy of token for patterns with too many variants:
+// the entropy given which alternatives are picked, which does not credit the choices themselves.
+func fallbackEntropy(token Token) float64 {
+	switch t := token.(type) {
+	case *SequenceToken:
+		total := 0.0
+
+		for _, inner := range t.Tokens {
+			total += fallbackEntropy(inner)
+		}
+
+		return total
+	case *RepeatToken:
+		return float64(max(t.Count, 0)) * fallbackEntropy(t.Token)
+	case *ChoiceToken:
+		return fallbackChoiceEntropy(t.Options)
+	case *OptionalToken:
+		return fallbackChoiceEntropy([]Token{t.Token, &LiteralToken{}})
+	default:
+		return token.EntropyBits()
+	}
+}
+
+// fallbackChoiceEntropy returns the fallbackEntropy of a choice between options.
+func fallbackChoiceEntropy(options []Token) float64 {
+	total := 0.0
+
+	for i, p := range choiceProbabilities(options) {
+		total += p * fallbackEntropy(options[i])
+	}
+
+	return total
+}
+
+// fallbackLengths returns the length classes of token matching fallbackEntropy.
+func fallbackLengths(token Token) ([]lengthClass, bool) {
+	switch t := token.(type) {
+	case *SequenceToken:
+		return fallbackJoinedLengths(t.Tokens)
+	case *RepeatToken:
+		return fallbackJoinedLengths(repeated(t.Token, t.Count))
+	case *ChoiceToken:
+		return fallbackChoiceLengths(t.Options)
+	case *OptionalToken:
+		return fallbackChoiceLengths([]Token{t.Token, &LiteralToken{}})
+	default:
+		return outputLengths(token)
+	}
+}
+
+// fallbackJoinedLengths returns the fallbackLengths of tokens generated one after another.
+func fallbackJoinedLengths(tokens []Token) ([]lengthClass, bool) {
+	total := fixedLength(0, 0)
+
+	for _, token := range tokens {
+		classes, ok := fallbackLengths(token)
+		if !ok {
+			return nil, false
+		}
+
+		total = convolveLengths(total, classes)
+	}
+
+	return total, true
+}
+
+// fallbackChoiceLengths returns the fallbackLengths of a choice between options.
+func fallbackChoiceLengths(options []Token) ([]lengthClass, bool) {
+	var mixed []lengthClass
+
+	for i, p := range choiceProbabilities(options) {
+		classes, ok := fallbackLengths(options[i])
+		if !ok {
+			return nil, false
+		}
+
+		mixed = addLengths(mixed, classes, p, 0)
+	}
+
+	return normalizeLengths(mixed), true
+}
+
+// addLengths adds classes, picked with probability weight, to the probability-weighted sums in mixed,
+// adding extra bits to their surprisal. normalizeLengths turns the sums back into length classes.
+func addLengths(mixed, classes []lengthClass, weight, extra float64) []lengthClass {
+	if len(classes) > len(mixed) {
+		mixed = append(mixed, make([]lengthClass, len(classes)-len(mixed))...)
+	}
+
+	for length, class := range classes {
+		p := weight * class.probability
+
+		mixed[length].probability += p
+		mixed[length].bits += p * (class.bits + extra)
+	}
+
+	return mixed
+}
+
+// normalizeLengths turns probability-weighted surprisal sums into averages.
+func normalizeLengths(mixed []lengthClass) []lengthClass {
+	for i := range mixed {
+		if mixed[i].probability > 0 {
+			mixed[i].bits /= mixed[i].probability
+		}
+	}
+
+	return mixed
+}
@@ -0,0 +1,42 @@
+package generate
+
+import (
+	"math"
+	"testing"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+)
+
+func TestChoiceEntropy(t *testing.T) {
+	t.Parallel()
+
+	// Picking C or D in proportion to 62 and 10 outcomes makes each digit twice as likely as a letter.
+	letters, digits := 52.0/72, 20.0/72
+
+	tests := []struct {
+		pattern string
+		want    float64
+	}{
+		{pattern: "D{4}|S{2}", want: math.Log2(1e4 + 26*26)},
+		{pattern: "S?", want: math.Log2(27)},
+		{pattern: "D|D", want: math.Log2(10)},
+		{pattern: "W:title|W:lower", want: math.Log2(2 * 7776)},
+		{pattern: "(W:upper D)|(W:lower D)", want: math.Log2(2*7776) + math.Log2(10)},
+		{pattern: "C|D", want: -letters*math.Log2(1.0/72) - digits*math.Log2(2.0/72)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			t.Parallel()
+
+			pattern, err := NewPatternBuilder(dictionary.EFF(), "-").BuildFromDSL(test.pattern)
+			if err != nil {
+				t.Fatalf("BuildFromDSL(%q) error = %v", test.pattern, err)
+			}
+
+			if got := pattern.EntropyBits(); math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("EntropyBits() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,10 @@
 package generate
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
+	"io"
 	"strings"
 
 	"github.com/idelchi/pwgen/internal/dictionary"
@@ -13,18 +13,31 @@ import (
 // Pattern represents a passphrase generation pattern.
 type Pattern struct {
 	Tokens []Token
+	// Source supplies the randomness for Generate; nil means crypto/rand.Reader.
+	Source io.Reader
 }
 
-// Generate creates a passphrase from this pattern.
+// Generate creates a passphrase from this pattern using its source.
 func (p *Pattern) Generate() (string, error) {
+	if p.Source == nil {
+		return p.GenerateFrom(rand.Reader)
+	}
+
+	return p.GenerateFrom(p.Source)
+}
+
+// GenerateFrom creates a passphrase from this pattern, drawing randomness from source.
+// A deterministic source, such as a derived key stream, always gives the same passphrase.
+func (p *Pattern) GenerateFrom(source io.Reader) (string, error) {
 	if len(p.Tokens) == 0 {
 		return "", errors.New("pattern is empty")
 	}
 
+	source = NewGeneration(source)
 	parts := make([]string, 0, len(p.Tokens))
 
 	for _, token := range p.Tokens {
-		part, err := token.Generate()
+		part, err := token.Generate(source)
 		if err != nil {
 			return "", fmt.Errorf("generating token %s: %w", token.Type(), err)
 		}
@@ -35,46 +48,182 @@ func (p *Pattern) Generate() (string, error) {
 	return strings.Join(parts, ""), nil
 }
 
-// EntropyBits calculates the total entropy of this pattern.
-func (p *Pattern) EntropyBits() float64 {
-	total := 0.0
+// Generation is the source of randomness for generating one passphrase token by token.
+// It remembers what the tokens generated so far wrote, so that first-upper casing title-cases
+// only the first word written, wherever choices and shuffles put it. GenerateFrom uses one per passphrase.
+type Generation struct {
+	io.Reader
 
-	for _, token := range p.Tokens {
-		total += token.EntropyBits()
+	// capitalized reports whether a first-upper word has been written.
+	capitalized bool
+}
+
+// NewGeneration starts generating a passphrase with randomness from source.
+func NewGeneration(source io.Reader) *Generation {
+	if generation, ok := source.(*Generation); ok {
+		return generation
 	}
 
-	return total
+	return &Generation{Reader: source}
+}
+
+// Keep records that token was written with a value kept from an earlier passphrase,
+// as for a locked column, so that later first-upper words are not capitalized again.
+func (g *Generation) Keep(token Token) {
+	walkTokens([]Token{token}, func(token Token) {
+		switch t := token.(type) {
+		case *WordToken:
+			g.capitalized = g.capitalized || t.Casing == CaseFirstUpper
+		case *SyllableToken:
+			g.capitalized = g.capitalized || t.Casing == CaseFirstUpper
+		}
+	})
 }
 
-// String returns a human-readable description of the pattern.
+// wordCasing returns the casing to write a word in: a first-upper word is title-cased if it is
+// the first one written for the passphrase, or generated on its own, and lowercased otherwise.
+func wordCasing(style CaseStyle, source io.Reader) CaseStyle {
+	if style != CaseFirstUpper {
+		return style
+	}
+
+	generation, ok := source.(*Generation)
+
+	switch {
+	case !ok:
+		return CaseTitle
+	case generation.capitalized:
+		return CaseLower
+	default:
+		generation.capitalized = true
+
+		return CaseTitle
+	}
+}
+
+// EntropyBits calculates the total entropy of this pattern: the sum of the entropies of its tokens,
+// unless choices or optional elements can produce the same passphrase in several ways, as in "D? D?".
+func (p *Pattern) EntropyBits() float64 {
+	return tokensEntropy(p.Tokens)
+}
+
+// String returns the pattern in canonical DSL syntax. BuildFromDSL parses it back
+// into an equivalent pattern, given the same default dictionary.
 func (p *Pattern) String() string {
 	parts := make([]string, 0, len(p.Tokens))
 
 	for _, token := range p.Tokens {
-		parts = append(parts, token.Type())
+		parts = append(parts, token.DSL())
 	}
 
 	return strings.Join(parts, " ")
 }
 
+// Explanation breaks the entropy of a pattern down by token.
+type Explanation struct {
+	Pattern string             `json:"pattern"`
+	Entropy float64            `json:"entropy"`
+	Tokens  []TokenExplanation `json:"tokens"`
+}
+
+// TokenExplanation describes a single token of a pattern and its entropy contribution.
+type TokenExplanation struct {
+	DSL     string  `json:"dsl"`
+	Type    string  `json:"type"`
+	Entropy float64 `json:"entropy"`
+}
+
+// Explain returns the canonical DSL of the pattern and the entropy contributed by each token.
+func (p *Pattern) Explain() Explanation {
+	tokens := make([]TokenExplanation, 0, len(p.Tokens))
+
+	for _, token := range p.Tokens {
+		tokens = append(tokens, TokenExplanation{
+			DSL:     token.DSL(),
+			Type:    token.Type(),
+			Entropy: token.EntropyBits(),
+		})
+	}
+
+	return Explanation{
+		Pattern: p.String(),
+		Entropy: p.EntropyBits(),
+		Tokens:  tokens,
+	}
+}
+
+// DictionaryResolver looks up a dictionary by the name used in a pattern, as in "W@nouns".
+type DictionaryResolver func(name string) (dictionary.Dictionary, error)
+
 // PatternBuilder helps construct patterns from various inputs.
 type PatternBuilder struct {
 	defaultDict dictionary.Dictionary
 	defaultSep  string
+	resolve     DictionaryResolver
+	resolved    map[string]dictionary.Dictionary
+	source      io.Reader
+	// wordFilter and blocklist are the word filters set with SetWordFilter.
+	wordFilter dictionary.FilterOptions
+	blocklist  string
 }
 
 // NewPatternBuilder creates a new pattern builder.
+// Dictionaries named in patterns are resolved with dictionary.GetDictionary.
 func NewPatternBuilder(defaultDict dictionary.Dictionary, defaultSep string) *PatternBuilder {
 	return &PatternBuilder{
 		defaultDict: defaultDict,
 		defaultSep:  defaultSep,
+		resolve:     dictionary.GetDictionary,
+		resolved:    make(map[string]dictionary.Dictionary),
+	}
+}
+
+// SetResolver changes how dictionaries named in patterns are looked up.
+func (pb *PatternBuilder) SetResolver(resolve DictionaryResolver) {
+	pb.resolve = resolve
+	pb.resolved = make(map[string]dictionary.Dictionary)
+}
+
+// SetSource sets the source of randomness of the patterns built from now on; nil means crypto/rand.Reader.
+func (pb *PatternBuilder) SetSource(source io.Reader) {
+	pb.source = source
+}
+
+// SetWordFilter sets word filters for every word of the patterns built from now on,
+// except for the filters a word of a pattern sets itself, as "W<3->" does for the minimum length.
+// blocklist is the path filter.Blocklist was loaded from.
+// The words carry the filters in their DSL, so the patterns read back the same.
+func (pb *PatternBuilder) SetWordFilter(filter dictionary.FilterOptions, blocklist string) {
+	pb.wordFilter = filter
+	pb.blocklist = blocklist
+}
+
+// dictionary returns the named dictionary, or the default one for an empty name.
+// Each name is resolved once per builder.
+func (pb *PatternBuilder) dictionary(name string) (dictionary.Dictionary, error) {
+	if name == "" {
+		return pb.defaultDict, nil
+	}
+
+	if dict, ok := pb.resolved[name]; ok {
+		return dict, nil
+	}
+
+	dict, err := pb.resolve(name)
+	if err != nil {
+		return nil, err
 	}
+
+	pb.resolved[name] = dict
+
+	return dict, nil
 }
 
 // BuildFromOptions creates a pattern from CLI options.
+// The placement ("end", "random" or "inside") decides where digits and symbols go.
 func (pb *PatternBuilder) BuildFromOptions(
 	words, digits, symbols int,
-	casing, sep string,
+	casing, sep, placement string,
 	kebab, snake, camel bool,
 ) (*Pattern, error) {
 	if words == 0 && digits == 0 && symbols == 0 {
@@ -86,6 +235,11 @@ func (pb *PatternBuilder) BuildFromOptions(
 		return nil, err
 	}
 
+	place, err := ParsePlacement(placement)
+	if err != nil {
+		return nil, err
+	}
+
 	// Determine separator
 	actualSep := pb.defaultSep
 
@@ -102,42 +256,57 @@ func (pb *PatternBuilder) BuildFromOptions(
 		actualSep = ""
 	}
 
-	// Estimate capacity: words + separators + digits + symbols
-	capacity := words + max(words-1, 0) + digits + symbols
-	tokens := make([]Token, 0, capacity)
+	// Words, then digits, then symbols
+	items := make([]Token, 0, words+digits+symbols)
 
-	// Add words with separators
-	for i := range words {
-		if i > 0 && actualSep != "" {
-			tokens = append(tokens, &SeparatorToken{Value: actualSep})
+	for range words {
+		word, err := pb.wordToken("", dictionary.FilterOptions{}, "", caseStyle)
+		if err != nil {
+			return nil, err
 		}
 
-		tokens = append(tokens, &WordToken{Dict: pb.defaultDict, Casing: caseStyle})
+		items = append(items, word)
 	}
 
-	// Add digits with separators
 	for range digits {
-		if len(tokens) > 0 && actualSep != "" {
-			tokens = append(tokens, &SeparatorToken{Value: actualSep})
-		}
-
-		tokens = append(tokens, &DigitToken{Count: 1})
+		items = append(items, &DigitToken{Count: 1})
 	}
 
-	// Add symbols with separators
 	for range symbols {
-		if len(tokens) > 0 && actualSep != "" {
-			tokens = append(tokens, &SeparatorToken{Value: actualSep})
+		items = append(items, &SymbolToken{Count: 1, Charset: DefaultSymbolCharset})
+	}
+
+	var tokens []Token
+
+	switch place {
+	case PlacementRandom:
+		tokens = []Token{&ShuffleToken{Separator: actualSep, Tokens: items}}
+	case PlacementInside:
+		inside := &InsideToken{Separator: actualSep, Tokens: items}
+		if err := inside.validate(); err != nil {
+			return nil, err
 		}
 
-		tokens = append(tokens, &SymbolToken{Count: 1, Charset: DefaultSymbolCharset})
+		tokens = []Token{inside}
+	default:
+		tokens = make([]Token, 0, 2*len(items)-1)
+
+		// Add items with separators
+		for _, token := range items {
+			if len(tokens) > 0 && actualSep != "" {
+				tokens = append(tokens, &SeparatorToken{Value: actualSep})
+			}
+
+			tokens = append(tokens, token)
+		}
 	}
 
-	return &Pattern{Tokens: tokens}, nil
+	return &Pattern{Tokens: tokens, Source: pb.source}, nil
 }
 
 // BuildFromDSL creates a pattern from a DSL string.
-// Example DSL: "W:title SEP W:lower SEP DD{2} SEP S".
+// Example DSL: "W:title SEP W:lower SEP D{2} SEP S", "W@adjectives:title SEP W@nouns"
+// or "\"corp-\" (W SEP(\".\")){3} D{4}|S{2}".
 func (pb *PatternBuilder) BuildFromDSL(dsl string) (*Pattern, error) {
 	if strings.TrimSpace(dsl) == "" {
 		return nil, errors.New("pattern DSL cannot be empty")
@@ -148,135 +317,87 @@ func (pb *PatternBuilder) BuildFromDSL(dsl string) (*Pattern, error) {
 		return nil, fmt.Errorf("parsing DSL pattern: %w", err)
 	}
 
-	return &Pattern{Tokens: tokens}, nil
+	return &Pattern{Tokens: tokens, Source: pb.source}, nil
 }
 
-// parseDSL parses a DSL string into tokens.
-func (pb *PatternBuilder) parseDSL(dsl string) ([]Token, error) {
-	// Split on whitespace and process each element
-	elements := strings.Fields(dsl)
-
-	tokens := make([]Token, 0, len(elements))
-
-	for _, element := range elements {
-		token, err := pb.parseElement(element)
-		if err != nil {
-			return nil, fmt.Errorf("parsing element %q: %w", element, err)
+// walkTokens calls visit for every token in pattern order, depth first,
+// including the tokens inside sequences, repetitions, choices and placements.
+func walkTokens(tokens []Token, visit func(Token)) {
+	for _, token := range tokens {
+		visit(token)
+
+		switch t := token.(type) {
+		case *SequenceToken:
+			walkTokens(t.Tokens, visit)
+		case *ChoiceToken:
+			walkTokens(t.Options, visit)
+		case *RepeatToken:
+			walkTokens([]Token{t.Token}, visit)
+		case *OptionalToken:
+			walkTokens([]Token{t.Token}, visit)
+		case *ShuffleToken:
+			walkTokens(t.Tokens, visit)
+		case *InsideToken:
+			walkTokens(t.Tokens, visit)
 		}
-
-		tokens = append(tokens, token)
 	}
-
-	return tokens, nil
 }
 
-// parseElement parses a single DSL element.
-//
-//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
-func (pb *PatternBuilder) parseElement(element string) (Token, error) {
-	element = strings.TrimSpace(element)
-
-	// Handle SEP (separator)
-	if element == "SEP" {
-		return &SeparatorToken{Value: pb.defaultSep}, nil
+// wordToken creates a word token from the named dictionary (the default one if empty),
+// restricted to the words that satisfy filter and the builder's word filters it leaves unset.
+// blocklist is the path filter.Blocklist was loaded from.
+func (pb *PatternBuilder) wordToken(
+	source string,
+	filter dictionary.FilterOptions,
+	blocklist string,
+	casing CaseStyle,
+) (*WordToken, error) {
+	if filter.MinLength == 0 {
+		filter.MinLength = pb.wordFilter.MinLength
 	}
 
-	// Handle W (word) with optional casing
-	if strings.HasPrefix(element, "W") {
-		return pb.parseWordToken(element)
+	if filter.MaxLength == 0 {
+		filter.MaxLength = pb.wordFilter.MaxLength
 	}
 
-	// Handle D (digit) with optional count
-	if strings.HasPrefix(element, "D") {
-		return pb.parseDigitToken(element)
+	if filter.Include == nil {
+		filter.Include = pb.wordFilter.Include
 	}
 
-	// Handle S (symbol) with optional count
-	if strings.HasPrefix(element, "S") {
-		return pb.parseSymbolToken(element)
+	if filter.Exclude == nil {
+		filter.Exclude = pb.wordFilter.Exclude
 	}
 
-	return nil, fmt.Errorf("unknown pattern element: %q", element)
-}
-
-// parseWordToken parses word tokens like "W", "W:title", "W:mixed".
-//
-//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
-func (pb *PatternBuilder) parseWordToken(element string) (Token, error) {
-	parts := strings.Split(element, ":")
-
-	if len(parts) == 1 {
-		// Just "W"
-		return &WordToken{Dict: pb.defaultDict, Casing: CaseLower}, nil
+	if blocklist == "" {
+		filter.Blocklist, blocklist = pb.wordFilter.Blocklist, pb.blocklist
 	}
 
-	if len(parts) != minWordsRequired {
-		return nil, fmt.Errorf("invalid word token format: %q", element)
-	}
-
-	caseStyle, err := ParseCaseStyle(parts[1])
+	dict, err := pb.dictionary(source)
 	if err != nil {
-		return nil, fmt.Errorf("invalid casing in word token %q: %w", element, err)
-	}
-
-	return &WordToken{Dict: pb.defaultDict, Casing: caseStyle}, nil
-}
-
-// parseDigitToken parses digit tokens like "D", "DD{2}", "D{3}".
-//
-//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
-func (pb *PatternBuilder) parseDigitToken(element string) (Token, error) {
-	// Match patterns like "D", "DD", "DDD", "D{n}", "DD{n}"
-	re := regexp.MustCompile(`^D+(?:\{(\d+)\})?$`)
-	matches := re.FindStringSubmatch(element)
-
-	if matches == nil {
-		return nil, fmt.Errorf("invalid digit token format: %q", element)
+		return nil, fmt.Errorf("resolving dictionary %q: %w", source, err)
 	}
 
-	count := strings.Count(element, "D")
-
-	// If there's a {n} modifier, use that count
-	if matches[1] != "" {
-		var err error
-
-		count, err = strconv.Atoi(matches[1])
-		if err != nil {
-			return nil, fmt.Errorf("invalid digit count in %q: %w", element, err)
-		}
-	}
-
-	if count <= 0 {
-		return nil, fmt.Errorf("digit count must be positive in %q", element)
-	}
-
-	return &DigitToken{Count: count}, nil
-}
-
-// parseSymbolToken parses symbol tokens like "S", "S{2}".
-//
-//nolint:ireturn // Token interface is required for polymorphism in pattern parsing
-func (pb *PatternBuilder) parseSymbolToken(element string) (Token, error) {
-	if element == "S" {
-		return &SymbolToken{Count: 1, Charset: DefaultSymbolCharset}, nil
+	dict, err = dictionary.Filter(dict, filter)
+	if err != nil {
+		return nil, fmt.Errorf("filtering dictionary: %w", err)
 	}
 
-	// Match pattern like "S{n}"
-	re := regexp.MustCompile(`^S\{(\d+)\}$`)
-	matches := re.FindStringSubmatch(element)
-
-	if matches == nil {
-		return nil, fmt.Errorf("invalid symbol token format: %q", element)
+	token := &WordToken{
+		Dict:      dict,
+		Casing:    casing,
+		Source:    source,
+		MinLength: filter.MinLength,
+		MaxLength: filter.MaxLength,
+		Blocklist: blocklist,
 	}
 
-	count, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return nil, fmt.Errorf("invalid symbol count in %q: %w", element, err)
+	if filter.Include != nil {
+		token.Include = filter.Include.String()
 	}
 
-	if count <= 0 {
-		return nil, fmt.Errorf("symbol count must be positive in %q", element)
+	if filter.Exclude != nil {
+		token.Exclude = filter.Exclude.String()
 	}
 
-	return &SymbolToken{Count: count, Charset: DefaultSymbolCharset}, nil
+	return token, nil
 }
@@ -0,0 +1,372 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/idelchi/pwgen/internal/random"
+)
+
+// Placement controls where BuildFromOptions puts digits and symbols relative to the words.
+type Placement int
+
+const (
+	// PlacementEnd appends all digits, then all symbols, after the words.
+	PlacementEnd Placement = iota
+	// PlacementRandom interleaves digits and symbols between the words in a random order.
+	PlacementRandom
+	// PlacementInside inserts each digit and symbol inside a word at a random position.
+	PlacementInside
+)
+
+// String returns the string representation of the placement.
+func (p Placement) String() string {
+	switch p {
+	case PlacementEnd:
+		return "end"
+	case PlacementRandom:
+		return "random"
+	case PlacementInside:
+		return "inside"
+	default:
+		return "unknown"
+	}
+}
+
+// ParsePlacement parses a placement from a string.
+func ParsePlacement(str string) (Placement, error) {
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "", "end":
+		return PlacementEnd, nil
+	case "random":
+		return PlacementRandom, nil
+	case "inside":
+		return PlacementInside, nil
+	default:
+		return PlacementEnd, fmt.Errorf("unknown placement: %q (expected end, random or inside)", str)
+	}
+}
+
+// lengthToken is implemented by tokens whose output can take digits and symbols inside it.
+type lengthToken interface {
+	Token
+
+	// lengthDistribution returns the probability of each output length in characters,
+	// indexed by length.
+	lengthDistribution() []float64
+
+	// lengthClasses returns the probability and average entropy of the outputs of each length.
+	lengthClasses() []lengthClass
+}
+
+// ShuffleToken generates its tokens in a random order joined by a separator,
+// as in SHUFFLE("-", W W W D S).
+//
+// Every distinct arrangement is equally likely. Tokens with the same DSL are interchangeable,
+// so the arrangement adds log2 of the multinomial coefficient n! / (k1! k2! ...) bits,
+// assuming the outputs of different tokens can be told apart.
+type ShuffleToken struct {
+	Separator string
+	Tokens    []Token
+}
+
+// Generate produces the values of all tokens in a random order.
+func (s *ShuffleToken) Generate(source io.Reader) (string, error) {
+	if len(s.Tokens) == 0 {
+		return "", errors.New("shuffle has no tokens")
+	}
+
+	order, err := randomPermutation(source, len(s.Tokens))
+	if err != nil {
+		return "", err
+	}
+
+	parts := make([]string, 0, len(s.Tokens))
+
+	for _, index := range order {
+		part, err := s.Tokens[index].Generate(source)
+		if err != nil {
+			return "", err
+		}
+
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, s.Separator), nil
+}
+
+// EntropyBits returns the entropy of all tokens plus that of their arrangement.
+func (s *ShuffleToken) EntropyBits() float64 {
+	total := 0.0
+
+	for _, token := range s.Tokens {
+		total += token.EntropyBits()
+	}
+
+	return total + arrangementBits(s.Tokens)
+}
+
+// Type returns a description of this token type.
+func (s *ShuffleToken) Type() string {
+	return "shuffle" + joinedTypes(s.Tokens)
+}
+
+// DSL returns the shuffle in pattern DSL syntax, e.g. SHUFFLE("-", W W D S).
+func (s *ShuffleToken) DSL() string {
+	return "SHUFFLE(" + joinedDSL(s.Separator, s.Tokens) + ")"
+}
+
+// InsideToken generates its words joined by a separator and inserts each of its other tokens
+// inside a word, as in INSIDE("-", W W W D S).
+//
+// Other tokens go into distinct gaps between two letters of a word, every distinct
+// placement being equally likely. With G gaps and k inserted tokens this adds
+// log2(C(G, k)) bits, averaged over the word lengths, plus the arrangement of the
+// inserted tokens as for ShuffleToken, assuming they can be told apart from letters.
+type InsideToken struct {
+	Separator string
+	Tokens    []Token
+}
+
+// Generate produces the words and inserts the other tokens at random positions inside them.
+func (t *InsideToken) Generate(source io.Reader) (string, error) {
+	hosts, inserts := t.split()
+	if len(hosts) == 0 {
+		return "", errors.New("inside has no words to insert into")
+	}
+
+	words := make([][]string, len(hosts))
+	gaps := make([][2]int, 0)
+
+	for i, host := range hosts {
+		word, err := host.Generate(source)
+		if err != nil {
+			return "", err
+		}
+
+		for _, r := range word {
+			words[i] = append(words[i], string(r))
+		}
+
+		for position := 1; position < len(words[i]); position++ {
+			gaps = append(gaps, [2]int{i, position})
+		}
+	}
+
+	if len(gaps) < len(inserts) {
+		return "", fmt.Errorf("not enough room inside the words for %d insertions", len(inserts))
+	}
+
+	// Pick distinct gaps: the first len(inserts) entries of a random permutation.
+	order, err := randomPermutation(source, len(gaps))
+	if err != nil {
+		return "", err
+	}
+
+	chosen := make([][2]int, 0, len(inserts))
+
+	for _, index := range order[:len(inserts)] {
+		chosen = append(chosen, gaps[index])
+	}
+
+	// Insert from the back so that earlier positions stay valid.
+	slices.SortFunc(chosen, func(a, b [2]int) int {
+		if a[0] != b[0] {
+			return b[0] - a[0]
+		}
+
+		return b[1] - a[1]
+	})
+
+	insertOrder, err := randomPermutation(source, len(inserts))
+	if err != nil {
+		return "", err
+	}
+
+	for i, gap := range chosen {
+		part, err := inserts[insertOrder[i]].Generate(source)
+		if err != nil {
+			return "", err
+		}
+
+		words[gap[0]] = slices.Insert(words[gap[0]], gap[1], part)
+	}
+
+	parts := make([]string, 0, len(words))
+
+	for _, word := range words {
+		parts = append(parts, strings.Join(word, ""))
+	}
+
+	return strings.Join(parts, t.Separator), nil
+}
+
+// EntropyBits returns the entropy of all tokens plus that of the insertion positions.
+func (t *InsideToken) EntropyBits() float64 {
+	hosts, inserts := t.split()
+
+	total := 0.0
+
+	for _, token := range t.Tokens {
+		total += token.EntropyBits()
+	}
+
+	if len(inserts) == 0 {
+		return total
+	}
+
+	// Distribution of the total number of gaps over all words.
+	gaps := []float64{1}
+
+	for _, host := range hosts {
+		lengths := host.lengthDistribution()
+		next := make([]float64, len(gaps)+len(lengths))
+
+		for sum, p := range gaps {
+			for length, q := range lengths {
+				next[sum+max(length-1, 0)] += p * q
+			}
+		}
+
+		gaps = next
+	}
+
+	positions := 0.0
+
+	for count, p := range gaps {
+		if count >= len(inserts) {
+			positions += p * log2Binomial(count, len(inserts))
+		}
+	}
+
+	return total + positions + arrangementBits(inserts)
+}
+
+// Type returns a description of this token type.
+func (t *InsideToken) Type() string {
+	return "inside" + joinedTypes(t.Tokens)
+}
+
+// DSL returns the insertion in pattern DSL syntax, e.g. INSIDE("-", W W D S).
+func (t *InsideToken) DSL() string {
+	return "INSIDE(" + joinedDSL(t.Separator, t.Tokens) + ")"
+}
+
+// validate checks that the shortest possible words leave room for every insertion.
+func (t *InsideToken) validate() error {
+	hosts, inserts := t.split()
+	if len(hosts) == 0 {
+		return errors.New("INSIDE needs at least one word (W or P) to insert into")
+	}
+
+	room := 0
+
+	for _, host := range hosts {
+		lengths := host.lengthDistribution()
+
+		shortest := slices.IndexFunc(lengths, func(p float64) bool { return p > 0 })
+		room += max(shortest-1, 0)
+	}
+
+	if room < len(inserts) {
+		return fmt.Errorf("not enough room inside the words for %d insertions (at most %d)", len(inserts), room)
+	}
+
+	return nil
+}
+
+// split separates the words, which take insertions, from the tokens inserted into them.
+func (t *InsideToken) split() ([]lengthToken, []Token) {
+	var (
+		hosts   []lengthToken
+		inserts []Token
+	)
+
+	for _, token := range t.Tokens {
+		if host, ok := token.(lengthToken); ok {
+			hosts = append(hosts, host)
+		} else {
+			inserts = append(inserts, token)
+		}
+	}
+
+	return hosts, inserts
+}
+
+// arrangementBits returns log2 of the number of distinct orders of tokens,
+// treating tokens with the same DSL as interchangeable.
+func arrangementBits(tokens []Token) float64 {
+	counts := make(map[string]int)
+
+	for _, token := range tokens {
+		counts[token.DSL()]++
+	}
+
+	bits := log2Factorial(len(tokens))
+
+	for _, count := range counts {
+		bits -= log2Factorial(count)
+	}
+
+	return bits
+}
+
+// log2Factorial returns log2(n!).
+func log2Factorial(n int) float64 {
+	lgamma, _ := math.Lgamma(float64(n) + 1)
+
+	return lgamma / math.Ln2
+}
+
+// log2Binomial returns log2 of the binomial coefficient C(n, k).
+func log2Binomial(n, k int) float64 {
+	return log2Factorial(n) - log2Factorial(k) - log2Factorial(n-k)
+}
+
+// randomPermutation returns a uniformly random permutation of 0..n-1.
+func randomPermutation(source io.Reader, n int) ([]int, error) {
+	order := make([]int, n)
+
+	for i := range order {
+		order[i] = i
+	}
+
+	// Fisher-Yates shuffle
+	for i := n - 1; i > 0; i-- {
+		j, err := random.Int(source, i+1)
+		if err != nil {
+			return nil, fmt.Errorf("generating random order: %w", err)
+		}
+
+		order[i], order[j] = order[j], order[i]
+	}
+
+	return order, nil
+}
+
+// joinedTypes returns the types of tokens, space separated in parentheses.
+func joinedTypes(tokens []Token) string {
+	parts := make([]string, 0, len(tokens))
+
+	for _, token := range tokens {
+		parts = append(parts, token.Type())
+	}
+
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// joinedDSL returns the quoted separator followed by the DSL of tokens, as in "-", W W D.
+func joinedDSL(separator string, tokens []Token) string {
+	parts := make([]string, 0, len(tokens))
+
+	for _, token := range tokens {
+		parts = append(parts, token.DSL())
+	}
+
+	return strconv.Quote(separator) + ", " + strings.Join(parts, " ")
+}
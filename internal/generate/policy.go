@@ -0,0 +1,327 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/idelchi/pwgen/internal/random"
+)
+
+const (
+	// policySamples is the number of passphrases generated to estimate how often a policy accepts.
+	policySamples = 1000
+	// maxPolicyAttempts is the number of passphrases tried per result before giving up.
+	maxPolicyAttempts = 100000
+	// policySeed seeds the source of the sample passphrases, so that estimates are reproducible.
+	policySeed = 1
+	// policyConfidence is the z-score of the estimated acceptance rate's lower bound:
+	// the true rate is below it with a probability of 1%.
+	policyConfidence = 2.326
+)
+
+// Policy describes the composition rules a site imposes on passwords.
+//
+// A character that is neither a letter nor a digit counts as a symbol, including separators.
+// Zero values impose no rule.
+type Policy struct {
+	MinLength int `json:"minLength"`
+	MaxLength int `json:"maxLength"`
+	// MinEntropy is checked against the pattern's entropy, not per passphrase.
+	MinEntropy int `json:"minEntropy"`
+
+	RequireUpper  bool `json:"requireUpper"`
+	RequireLower  bool `json:"requireLower"`
+	RequireDigit  bool `json:"requireDigit"`
+	RequireSymbol bool `json:"requireSymbol"`
+
+	// AllowedSymbols, if set, are the only symbols that may appear.
+	AllowedSymbols string `json:"allowedSymbols"`
+	// Forbidden characters may not appear at all.
+	Forbidden string `json:"forbidden"`
+	// MaxRepeat is the longest allowed run of the same character, as in "aaa" (3).
+	MaxRepeat int `json:"maxRepeat"`
+}
+
+// LoadPolicy reads a policy from a JSON file such as
+//
+//	{"minLength": 12, "maxLength": 20, "requireDigit": true, "allowedSymbols": "-_!", "maxRepeat": 2}
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var policy Policy
+
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("parsing policy %q: %w", path, err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %q: %w", path, err)
+	}
+
+	return &policy, nil
+}
+
+// Validate checks that the policy's rules are consistent.
+func (p *Policy) Validate() error {
+	switch {
+	case p.MinLength < 0, p.MaxLength < 0, p.MinEntropy < 0, p.MaxRepeat < 0:
+		return errors.New("lengths, entropy and repeats cannot be negative")
+	case p.MaxLength > 0 && p.MinLength > p.MaxLength:
+		return fmt.Errorf("minimum length %d exceeds maximum %d", p.MinLength, p.MaxLength)
+	case p.RequireSymbol && p.AllowedSymbols != "" && !strings.ContainsFunc(p.AllowedSymbols, p.permitted):
+		return errors.New("a symbol is required but every allowed symbol is forbidden")
+	default:
+		return nil
+	}
+}
+
+// permitted reports whether r is not a forbidden character.
+func (p *Policy) permitted(r rune) bool {
+	return !strings.ContainsRune(p.Forbidden, r)
+}
+
+// composition reports whether the policy has rules on the passphrase itself, beyond MinEntropy.
+func (p *Policy) composition() bool {
+	rules := *p
+	rules.MinEntropy = 0
+
+	return rules != Policy{}
+}
+
+// Violations returns a description of every rule the passphrase breaks.
+func (p *Policy) Violations(passphrase string) []string {
+	var violations []string
+
+	length := utf8.RuneCountInString(passphrase)
+
+	if p.MinLength > 0 && length < p.MinLength {
+		violations = append(violations, fmt.Sprintf("shorter than %d characters", p.MinLength))
+	}
+
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, fmt.Sprintf("longer than %d characters", p.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+
+	run, longestRun := 0, 0
+	previous := utf8.RuneError
+
+	for _, r := range passphrase {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r):
+			hasSymbol = true
+
+			if p.AllowedSymbols != "" && !strings.ContainsRune(p.AllowedSymbols, r) {
+				violations = appendOnce(violations, fmt.Sprintf("symbol %q is not allowed", r))
+			}
+		}
+
+		if !p.permitted(r) {
+			violations = appendOnce(violations, fmt.Sprintf("character %q is forbidden", r))
+		}
+
+		if r == previous {
+			run++
+		} else {
+			run = 1
+		}
+
+		previous = r
+		longestRun = max(longestRun, run)
+	}
+
+	required := []struct {
+		required, present bool
+		class             string
+	}{
+		{p.RequireUpper, hasUpper, "an uppercase letter"},
+		{p.RequireLower, hasLower, "a lowercase letter"},
+		{p.RequireDigit, hasDigit, "a digit"},
+		{p.RequireSymbol, hasSymbol, "a symbol"},
+	}
+
+	for _, class := range required {
+		if class.required && !class.present {
+			violations = append(violations, "missing "+class.class)
+		}
+	}
+
+	if p.MaxRepeat > 0 && longestRun > p.MaxRepeat {
+		violations = append(violations, fmt.Sprintf("repeats a character more than %d times in a row", p.MaxRepeat))
+	}
+
+	return violations
+}
+
+// appendOnce appends violation unless it was already reported.
+func appendOnce(violations []string, violation string) []string {
+	if slices.Contains(violations, violation) {
+		return violations
+	}
+
+	return append(violations, violation)
+}
+
+// Allows reports whether the passphrase follows every rule of the policy.
+func (p *Policy) Allows(passphrase string) bool {
+	return len(p.Violations(passphrase)) == 0
+}
+
+// Entropy returns the entropy of the pattern's passphrases that follow the policy,
+// when the others are discarded and generated again.
+//
+// Discarding leaves a fraction rate of the outcomes, each kept one becoming 1/rate times more likely.
+// The entropy is computed exactly from the kept lengths and character classes when the pattern's
+// characters are known and the policy does not limit repeats. Otherwise rate is estimated from
+// sample passphrases and the entropy drops by -log2(rate) bits.
+func (p *Policy) Entropy(pattern *Pattern) (float64, error) {
+	if !p.composition() {
+		return pattern.EntropyBits(), nil
+	}
+
+	if classes, ok := patternLengths(pattern); ok && p.lengthOnly() {
+		entropy, rate := constrainedEntropy(classes, p.MinLength, p.MaxLength)
+
+		if rate*maxPolicyAttempts < 1 {
+			return 0, fmt.Errorf("too few passphrases of this pattern have %s; use fewer or shorter words",
+				p.lengthRange())
+		}
+
+		return entropy, nil
+	}
+
+	if outputs, ok := p.compositions(pattern); ok {
+		entropy, rate := p.accepted(outputs)
+
+		if rate*maxPolicyAttempts < 1 {
+			return 0, errors.New("too few passphrases of this pattern follow the policy; " +
+				"relax the policy or change the pattern")
+		}
+
+		return entropy, nil
+	}
+
+	rate, err := p.acceptanceRate(pattern)
+	if err != nil {
+		return 0, err
+	}
+
+	return max(pattern.EntropyBits()+math.Log2(rate), 0), nil
+}
+
+// acceptanceRate estimates the fraction of the pattern's passphrases the policy accepts
+// from sample passphrases.
+//
+// The samples are drawn from a fixed seed rather than the pattern's source, so the estimate
+// is the same on every run and seeded or derived passphrases are unaffected. The estimate is
+// the lower end of the rate's confidence interval, so that it rather understates the entropy.
+func (p *Policy) acceptanceRate(pattern *Pattern) (float64, error) {
+	source := random.NewSeeded(policySeed)
+	accepted := 0
+
+	for range policySamples {
+		passphrase, err := pattern.GenerateFrom(source)
+		if err != nil {
+			return 0, err
+		}
+
+		if p.Allows(passphrase) {
+			accepted++
+		}
+	}
+
+	if accepted == 0 {
+		return 0, fmt.Errorf("policy rejected all %d sample passphrases; relax the policy or change the pattern",
+			policySamples)
+	}
+
+	return wilsonLowerBound(accepted, policySamples), nil
+}
+
+// wilsonLowerBound returns the lower end of the Wilson score interval for a rate observed
+// as successes out of trials, at the confidence of policyConfidence.
+func wilsonLowerBound(successes, trials int) float64 {
+	n := float64(trials)
+	rate := float64(successes) / n
+	z2 := policyConfidence * policyConfidence
+
+	center := rate + z2/(2*n)
+	spread := policyConfidence * math.Sqrt(rate*(1-rate)/n+z2/(4*n*n))
+
+	return (center - spread) / (1 + z2/n)
+}
+
+// lengthRange describes the allowed lengths, as in "at most 20 characters".
+func (p *Policy) lengthRange() string {
+	switch {
+	case p.MaxLength == 0:
+		return fmt.Sprintf("at least %d characters", p.MinLength)
+	case p.MinLength == 0:
+		return fmt.Sprintf("at most %d characters", p.MaxLength)
+	default:
+		return fmt.Sprintf("between %d and %d characters", p.MinLength, p.MaxLength)
+	}
+}
+
+// lengthOnly reports whether the policy's only rules on the passphrase are its length bounds.
+func (p *Policy) lengthOnly() bool {
+	return (Policy{MinLength: p.MinLength, MaxLength: p.MaxLength, MinEntropy: p.MinEntropy}) == *p
+}
+
+// generateCompliant generates passphrases from the pattern until one is allowed by the policy
+// and not excluded, if exclude is set.
+// If rolls is not nil, it keeps only the dice rolls of the allowed passphrase.
+func (p *Policy) generateCompliant(
+	pattern *Pattern,
+	rolls *rollLog,
+	exclude func(string) (bool, error),
+) (string, error) {
+	for range maxPolicyAttempts {
+		rolls.reset()
+
+		passphrase, err := pattern.Generate()
+		if err != nil {
+			return "", err
+		}
+
+		if !p.Allows(passphrase) {
+			continue
+		}
+
+		if exclude == nil {
+			return passphrase, nil
+		}
+
+		excluded, err := exclude(passphrase)
+		if err != nil {
+			return "", err
+		}
+
+		if !excluded {
+			return passphrase, nil
+		}
+	}
+
+	return "", fmt.Errorf("no passphrase satisfied the policy after %d attempts", maxPolicyAttempts)
+}
@@ -0,0 +1,388 @@
+package generate
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+)
+
+// maxSegmentations bounds the ways of splitting a passphrase into tokens tried per dictionary and separator.
+const maxSegmentations = 256
+
+// RecognizedPattern is a pwgen pattern that generates the analyzed passphrase, as "gen" would have
+// built it from its options, and the entropy of that pattern.
+type RecognizedPattern struct {
+	Dictionary string  `json:"dictionary"`
+	Pattern    string  `json:"pattern"`
+	Words      int     `json:"words"`
+	Digits     int     `json:"digits"`
+	Symbols    int     `json:"symbols"`
+	Separator  string  `json:"separator"`
+	Casing     string  `json:"casing"`
+	Placement  string  `json:"placement"`
+	Entropy    float64 `json:"entropy"`
+}
+
+// Kinds of segments a passphrase is split into.
+const (
+	segmentWord = iota
+	segmentDigit
+	segmentSymbol
+)
+
+// segment is one token of a passphrase: a dictionary word as written, a digit or a symbol.
+type segment struct {
+	kind int
+	text string
+	// word is the dictionary word a word segment was written from.
+	word string
+}
+
+// lexicon looks up the words of a dictionary regardless of their casing.
+type lexicon struct {
+	dict      dictionary.Dictionary
+	lower     cases.Caser
+	words     map[string]string
+	maxLength int
+}
+
+// newLexicon indexes the words of dict by their lowercase form.
+func newLexicon(dict dictionary.Dictionary) *lexicon {
+	lex := &lexicon{
+		dict:  dict,
+		lower: cases.Lower(dict.Language()),
+		words: make(map[string]string, dict.Size()),
+	}
+
+	for _, word := range dict.Words() {
+		lex.words[lex.lower.String(word)] = word
+		lex.maxLength = max(lex.maxLength, len([]rune(word)))
+	}
+
+	return lex
+}
+
+// recognizePattern finds the pwgen pattern of lowest entropy that generates passphrase with words
+// from one of dicts, or nil if there is none. The passphrase is split into words, digits and symbols
+// around every possible separator, including none for camel-case passphrases; the casing style is
+// inferred from the words, and the placement from where the digits and symbols are.
+func recognizePattern(passphrase string, dicts []dictionary.Dictionary) *RecognizedPattern {
+	var best *RecognizedPattern
+
+	input := []rune(passphrase)
+
+	for _, dict := range dicts {
+		lex := newLexicon(dict)
+
+		for _, sep := range separatorCandidates(passphrase) {
+			consider := func(segments []segment, inside bool) {
+				if found := lex.pattern(segments, sep, inside); found != nil &&
+					(best == nil || found.Entropy < best.Entropy) {
+					best = found
+				}
+			}
+
+			lex.segment(input, []rune(sep), true, func(segments []segment) { consider(segments, false) })
+			lex.segmentInside(passphrase, sep, func(segments []segment) { consider(segments, true) })
+		}
+	}
+
+	return best
+}
+
+// separatorCandidates returns the separators a passphrase may have been joined with:
+// none, or any of its characters that is neither a letter nor a digit.
+func separatorCandidates(passphrase string) []string {
+	candidates := []string{""}
+
+	for _, r := range passphrase {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !slices.Contains(candidates, string(r)) {
+			candidates = append(candidates, string(r))
+		}
+	}
+
+	return candidates
+}
+
+// segment calls visit with up to maxSegmentations ways of splitting input into words, joined by sep.
+// With tokens, digits and symbols may stand between the words as well.
+func (lex *lexicon) segment(input, sep []rune, tokens bool, visit func([]segment)) {
+	var (
+		found    int
+		current  []segment
+		deadEnds = make(map[int]bool)
+	)
+
+	var walk func(start int) bool
+
+	walk = func(start int) bool {
+		// A separator at the end of the input leaves nothing to complete the split with.
+		if start == len(input) || found >= maxSegmentations || deadEnds[start] {
+			return false
+		}
+
+		complete := false
+
+		next := func(end int, seg segment) {
+			current = append(current, seg)
+
+			switch {
+			case end == len(input):
+				found++
+				complete = true
+
+				visit(slices.Clone(current))
+			case len(sep) == 0:
+				complete = walk(end) || complete
+			case hasPrefix(input[end:], sep):
+				complete = walk(end+len(sep)) || complete
+			}
+
+			current = current[:len(current)-1]
+		}
+
+		if tokens {
+			switch char := input[start]; {
+			case isDigitToken(char):
+				next(start+1, segment{kind: segmentDigit, text: string(char)})
+			case isSymbolToken(char):
+				next(start+1, segment{kind: segmentSymbol, text: string(char)})
+			}
+		}
+
+		for end := start + 1; end <= min(start+lex.maxLength, len(input)); end++ {
+			text := string(input[start:end])
+
+			if word, ok := lex.words[lex.lower.String(text)]; ok {
+				next(end, segment{kind: segmentWord, text: text, word: word})
+			}
+		}
+
+		if !complete {
+			deadEnds[start] = true
+		}
+
+		return complete
+	}
+
+	if len(input) > 0 {
+		walk(0)
+	}
+}
+
+// segmentInside calls visit with the ways of splitting passphrase into words joined by sep,
+// with digits and symbols inserted inside the words: never first or last in a word, nor next to each other.
+// The inserted digits and symbols are passed after the words.
+func (lex *lexicon) segmentInside(passphrase, sep string, visit func([]segment)) {
+	chunks := []string{passphrase}
+	if sep != "" {
+		chunks = strings.Split(passphrase, sep)
+	}
+
+	var (
+		stripped []rune
+		inserts  []segment
+	)
+
+	for _, chunk := range chunks {
+		runes := []rune(chunk)
+
+		for i, char := range runes {
+			if !isDigitToken(char) && !isSymbolToken(char) {
+				stripped = append(stripped, char)
+
+				continue
+			}
+
+			if i == 0 || i == len(runes)-1 || isDigitToken(runes[i-1]) || isSymbolToken(runes[i-1]) ||
+				isDigitToken(runes[i+1]) || isSymbolToken(runes[i+1]) {
+				return
+			}
+
+			kind := segmentSymbol
+			if isDigitToken(char) {
+				kind = segmentDigit
+			}
+
+			inserts = append(inserts, segment{kind: kind, text: string(char)})
+		}
+
+		stripped = append(stripped, []rune(sep)...)
+	}
+
+	if len(inserts) == 0 {
+		return
+	}
+
+	stripped = stripped[:len(stripped)-len([]rune(sep))]
+
+	lex.segment(stripped, []rune(sep), false, func(words []segment) {
+		visit(append(words, inserts...))
+	})
+}
+
+// pattern rebuilds the pattern that generates the segments joined by sep, or returns nil if there is none.
+// Words must come before digits and symbols for the "end" placement; other orders need "random" placement.
+func (lex *lexicon) pattern(segments []segment, sep string, inside bool) *RecognizedPattern {
+	var words []segment
+
+	digits, symbols := 0, 0
+	ordered := true
+
+	for i, seg := range segments {
+		switch seg.kind {
+		case segmentWord:
+			words = append(words, seg)
+		case segmentDigit:
+			digits++
+		case segmentSymbol:
+			symbols++
+		}
+
+		if i > 0 && seg.kind < segments[i-1].kind {
+			ordered = false
+		}
+	}
+
+	if len(words) < minWordsRequired {
+		return nil
+	}
+
+	casing, ok := inferCasing(words, lex.dict.Language())
+	if !ok {
+		return nil
+	}
+
+	if found := lex.mnemonic(words, digits+symbols, sep, casing); found != nil {
+		return found
+	}
+
+	placement := PlacementEnd
+
+	switch {
+	case inside:
+		placement = PlacementInside
+	case !ordered:
+		placement = PlacementRandom
+	}
+
+	pattern, err := NewPatternBuilder(lex.dict, sep).BuildFromOptions(
+		len(words), digits, symbols, casing.String(), sep, placement.String(), false, false, false)
+	if err != nil {
+		return nil
+	}
+
+	return &RecognizedPattern{
+		Dictionary: lex.dict.Name(),
+		Pattern:    pattern.String(),
+		Words:      len(words),
+		Digits:     digits,
+		Symbols:    symbols,
+		Separator:  sep,
+		Casing:     casing.String(),
+		Placement:  placement.String(),
+		Entropy:    pattern.EntropyBits(),
+	}
+}
+
+// mnemonic returns the pattern of a BIP39 mnemonic if the words are one: lowercase English BIP39 words
+// joined by spaces, without digits or symbols, whose checksum is valid. The checksum is determined
+// by the other bits, so the entropy is that of the mnemonic, not 11 bits per word.
+func (lex *lexicon) mnemonic(words []segment, tokens int, sep string, casing CaseStyle) *RecognizedPattern {
+	if tokens > 0 || sep != " " || casing != CaseLower || lex.dict.Name() != dictionary.BIP39English().Name() {
+		return nil
+	}
+
+	texts := make([]string, 0, len(words))
+
+	for _, seg := range words {
+		texts = append(texts, seg.text)
+	}
+
+	check := CheckMnemonic(strings.Join(texts, " "))
+	if !check.Valid {
+		return nil
+	}
+
+	token := &MnemonicToken{Bits: check.EntropyBits}
+
+	return &RecognizedPattern{
+		Dictionary: lex.dict.Name(),
+		Pattern:    token.DSL(),
+		Words:      len(words),
+		Separator:  sep,
+		Casing:     casing.String(),
+		Placement:  PlacementEnd.String(),
+		Entropy:    token.EntropyBits(),
+	}
+}
+
+// inferCasing returns the casing style of least entropy that writes every word as it appears.
+func inferCasing(words []segment, lang language.Tag) (CaseStyle, bool) {
+	styles := []CaseStyle{
+		CaseLower, CaseUpper, CaseTitle, CaseFirstUpper, CaseInvertedTitle, CaseAlternating, CaseRandomTitle, CaseMixed,
+	}
+
+	for _, style := range styles {
+		consistent := true
+
+		for i, seg := range words {
+			if !writesAs(style, i, seg, lang) {
+				consistent = false
+
+				break
+			}
+		}
+
+		if consistent {
+			return style, true
+		}
+	}
+
+	return CaseLower, false
+}
+
+// writesAs reports whether style can write the index-th word of a passphrase as it appears.
+func writesAs(style CaseStyle, index int, seg segment, lang language.Tag) bool {
+	cased := func(style CaseStyle) bool {
+		text, err := ApplyCasing(seg.word, style, lang, nil)
+
+		return err == nil && text == seg.text
+	}
+
+	switch style {
+	case CaseFirstUpper:
+		if index == 0 {
+			return cased(CaseTitle)
+		}
+
+		return cased(CaseLower)
+	case CaseRandomTitle:
+		return cased(CaseTitle) || cased(CaseLower)
+	case CaseMixed:
+		// Mixed casing uppercases the first letter rather than leave a word in lowercase
+		return !cased(CaseLower) || cased(CaseUpper)
+	default:
+		return cased(style)
+	}
+}
+
+// isDigitToken reports whether r can be the output of a digit token.
+func isDigitToken(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isSymbolToken reports whether r can be the output of a symbol token with the default charset.
+func isSymbolToken(r rune) bool {
+	return strings.ContainsRune(DefaultSymbolCharset, r)
+}
+
+// hasPrefix reports whether runes starts with prefix.
+func hasPrefix(runes, prefix []rune) bool {
+	return len(runes) >= len(prefix) && slices.Equal(runes[:len(prefix)], prefix)
+}
@@ -0,0 +1,76 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+)
+
+func TestRecognizedPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		passphrase string
+		// pattern is the recognized pattern, empty if none is expected.
+		pattern string
+		entropy float64
+	}{
+		{
+			name:       "generated passphrase",
+			passphrase: "Ion-OPPoNenT-EVASIOn-negATe",
+			pattern:    `W:mixed SEP("-") W:mixed SEP("-") W:mixed SEP("-") W:mixed`,
+		},
+		{
+			name:       "camel case with digits",
+			passphrase: "AnglesSternumStiffness89",
+			pattern:    "W:title W:title W:title D D",
+		},
+		{
+			name:       "mnemonic scored without its checksum",
+			passphrase: "plunge rug tower tell lemon wash panda cute solution ball gaze kite",
+			pattern:    "BIP39{128}",
+			entropy:    128,
+		},
+		{
+			name:       "mnemonic with an invalid checksum",
+			passphrase: "plunge rug tower tell lemon wash panda cute solution ball gaze gaze",
+			pattern: `W SEP(" ") W SEP(" ") W SEP(" ") W SEP(" ") W SEP(" ") W SEP(" ") W SEP(" ") W SEP(" ") ` +
+				`W SEP(" ") W SEP(" ") W SEP(" ") W`,
+			entropy: 132,
+		},
+		{
+			name:       "common password made of words",
+			passphrase: "Password2024!",
+		},
+		// A separator candidate at the end leaves nothing to split after it.
+		{name: "word and trailing symbol", passphrase: "zoom!"},
+		{name: "word and trailing period", passphrase: "hello."},
+		{name: "words with trailing separator", passphrase: "zoom-zoom-"},
+		{name: "digit and trailing symbol", passphrase: "1!"},
+		{name: "word with trailing separator", passphrase: "word-"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			calculator := NewEntropyCalculator()
+			calculator.SetPatternDictionaries([]dictionary.Dictionary{dictionary.EFF(), dictionary.BIP39English()})
+
+			recognized := calculator.CalculateEntropy(test.passphrase).Recognized
+
+			switch {
+			case test.pattern == "" && recognized != nil:
+				t.Errorf("Recognized = %q, want none", recognized.Pattern)
+			case test.pattern == "":
+			case recognized == nil:
+				t.Errorf("Recognized = none, want %q", test.pattern)
+			case recognized.Pattern != test.pattern:
+				t.Errorf("Recognized = %q, want %q", recognized.Pattern, test.pattern)
+			case test.entropy != 0 && recognized.Entropy != test.entropy:
+				t.Errorf("Recognized entropy = %v, want %v", recognized.Entropy, test.entropy)
+			}
+		})
+	}
+}
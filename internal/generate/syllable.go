@@ -0,0 +1,175 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+
+	"github.com/idelchi/pwgen/internal/random"
+)
+
+const (
+	// syllableOnsets are the consonant clusters that start a syllable.
+	// They contain no vowels, so concatenated syllables split back into them unambiguously.
+	syllableOnsets = "b c d f g h j k l m n p r s t v w z br ch cr dr fr gr pr sh st th tr"
+	// syllableVowels are the vowel sounds that end a syllable.
+	syllableVowels = "a e i o u ai ea oo ou"
+)
+
+// syllables is the table of all onset-vowel syllables.
+//
+//nolint:gochecknoglobals // Lazily built read-only table
+var syllables = sync.OnceValue(func() []string {
+	onsets := strings.Fields(syllableOnsets)
+	vowels := strings.Fields(syllableVowels)
+	table := make([]string, 0, len(onsets)*len(vowels))
+
+	for _, onset := range onsets {
+		for _, vowel := range vowels {
+			table = append(table, onset+vowel)
+		}
+	}
+
+	return table
+})
+
+// SyllableToken generates a pronounceable string of consonant-vowel syllables, as in "P{3}".
+//
+// Every syllable starts with consonants and ends with vowels, so each generated string
+// has exactly one split into syllables and the entropy is Count * log2(len(table)).
+type SyllableToken struct {
+	Count  int // Number of syllables to generate
+	Casing CaseStyle
+}
+
+// Generate produces Count random syllables with the specified casing.
+func (s *SyllableToken) Generate(source io.Reader) (string, error) {
+	if s.Count <= 0 {
+		return "", errors.New("syllable count must be positive")
+	}
+
+	table := syllables()
+
+	var result strings.Builder
+
+	for range s.Count {
+		idx, err := random.Int(source, len(table))
+		if err != nil {
+			return "", fmt.Errorf("generating random syllable: %w", err)
+		}
+
+		result.WriteString(table[idx])
+	}
+
+	return ApplyCasing(result.String(), wordCasing(s.Casing, source), language.Und, source)
+}
+
+// EntropyBits returns the entropy contributed by this syllable token.
+func (s *SyllableToken) EntropyBits() float64 {
+	if s.Count <= 0 {
+		return 0
+	}
+
+	// log2(syllables^count) = count * log2(syllables)
+	bits := float64(s.Count) * math.Log2(float64(len(syllables())))
+
+	switch s.Casing {
+	case CaseMixed:
+		bits += s.mixedCaseBits()
+	case CaseRandomTitle:
+		// Syllables always start with a cased ASCII letter.
+		bits++
+	default:
+	}
+
+	return bits
+}
+
+// mixedCaseBits averages the mixed casing entropy over the distribution of output lengths.
+func (s *SyllableToken) mixedCaseBits() float64 {
+	bits := 0.0
+
+	for length, p := range s.lengthDistribution() {
+		bits += p * mixedCaseBits(length, length, true)
+	}
+
+	return bits
+}
+
+// lengthDistribution returns the probability of each output length after Count syllables.
+func (s *SyllableToken) lengthDistribution() []float64 {
+	table := syllables()
+
+	// Probability of each syllable length, then of each total length after Count syllables.
+	var syllableLengths []float64
+
+	for _, syllable := range table {
+		if len(syllable) >= len(syllableLengths) {
+			syllableLengths = append(syllableLengths, make([]float64, len(syllable)-len(syllableLengths)+1)...)
+		}
+
+		syllableLengths[len(syllable)] += 1 / float64(len(table))
+	}
+
+	lengths := []float64{1}
+
+	for range s.Count {
+		next := make([]float64, len(lengths)+len(syllableLengths)-1)
+
+		for total, p := range lengths {
+			for length, q := range syllableLengths {
+				next[total+length] += p * q
+			}
+		}
+
+		lengths = next
+	}
+
+	return lengths
+}
+
+// lengthClasses returns the probability and average entropy of the outputs of each length.
+func (s *SyllableToken) lengthClasses() []lengthClass {
+	syllableBits := float64(s.Count) * math.Log2(float64(len(syllables())))
+	lengths := s.lengthDistribution()
+	classes := make([]lengthClass, len(lengths))
+
+	for length, p := range lengths {
+		classes[length] = lengthClass{probability: p, bits: syllableBits}
+
+		switch s.Casing {
+		case CaseMixed:
+			classes[length].bits += mixedCaseBits(length, length, true)
+		case CaseRandomTitle:
+			classes[length].bits++
+		default:
+		}
+	}
+
+	return classes
+}
+
+// Type returns a description of this token type.
+func (s *SyllableToken) Type() string {
+	return fmt.Sprintf("pronounceable(%s,%d)", s.Casing, s.Count)
+}
+
+// DSL returns the syllable token in pattern DSL syntax, e.g. "P{3}:title".
+func (s *SyllableToken) DSL() string {
+	dsl := "P"
+
+	if s.Count != 1 {
+		dsl = fmt.Sprintf("P{%d}", s.Count)
+	}
+
+	if s.Casing != CaseLower {
+		dsl += ":" + s.Casing.String()
+	}
+
+	return dsl
+}
@@ -1,19 +1,22 @@
 package generate
 
 import (
-	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
 	"math"
-	"math/big"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
 	"github.com/idelchi/pwgen/internal/dictionary"
+	"github.com/idelchi/pwgen/internal/random"
 )
 
 const (
@@ -25,49 +28,246 @@ const (
 
 // Token represents a single element in a passphrase.
 type Token interface {
-	// Generate produces a random value for this token.
-	Generate() (string, error)
+	// Generate produces a random value for this token, drawing randomness from source.
+	Generate(source io.Reader) (string, error)
 
 	// EntropyBits returns the entropy contributed by this token.
 	EntropyBits() float64
 
 	// Type returns a description of this token type.
 	Type() string
+
+	// DSL returns the token in pattern DSL syntax, as accepted by PatternBuilder.BuildFromDSL.
+	DSL() string
 }
 
 // WordToken generates random words from a dictionary.
 type WordToken struct {
 	Dict   dictionary.Dictionary
 	Casing CaseStyle
+	// Source is the dictionary name given in the pattern ("W@nouns"), empty for the default dictionary.
+	Source string
+	// MinLength and MaxLength are the word length bounds given in the pattern ("W<3-6>"), zero if unbounded.
+	// Dict is already restricted to them.
+	MinLength int
+	MaxLength int
+	// Include, Exclude and Blocklist are the regular expressions and the blocklist file given in the pattern
+	// (`W(include="^[a-m]+$", blocklist="offensive.txt")`), empty if unset. Dict is already restricted to them.
+	Include   string
+	Exclude   string
+	Blocklist string
+
+	// stats caches what the token derives from all words of Dict, as choices and policies
+	// ask for its entropy on every generation.
+	stats atomic.Pointer[wordStats]
+}
+
+// wordStats holds what a word token derives from all words of its dictionary for one casing style.
+type wordStats struct {
+	dict   dictionary.Dictionary
+	casing CaseStyle
+	bits   float64
+	// runes returns the characters the words can contain in any casing, computed on first use.
+	runes func() map[rune]bool
+	// outputs returns the words as the casing style can write them, computed on first use,
+	// or nil for mixed casing, which can write too many.
+	outputs func() map[string]bool
 }
 
 // Generate produces a random word with the specified casing.
-func (w *WordToken) Generate() (string, error) {
-	word, err := w.Dict.RandomWord()
+func (w *WordToken) Generate(source io.Reader) (string, error) {
+	word, err := w.Dict.RandomWord(source)
 	if err != nil {
 		return "", err
 	}
 
-	return ApplyCasing(word, w.Casing)
+	return ApplyCasing(word, wordCasing(w.Casing, source), w.Dict.Language(), source)
 }
 
-// EntropyBits returns the entropy contributed by this word token.
+// EntropyBits returns the entropy contributed by this word token: the dictionary's entropy plus,
+// for random casing styles, the casing entropy averaged over the dictionary's words.
 func (w *WordToken) EntropyBits() float64 {
-	return w.Dict.EntropyBits() + w.Casing.EntropyBits()
+	return w.wordStats().bits
+}
+
+// runes returns the characters the token's words can contain in any casing.
+func (w *WordToken) runes() map[rune]bool {
+	return w.wordStats().runes()
+}
+
+// outputs returns the words the token can produce, or nil if they are not listed.
+func (w *WordToken) outputs() map[string]bool {
+	return w.wordStats().outputs()
+}
+
+// casedWords returns words as the casing style can write them, or nil for mixed casing.
+// First-upper casing writes a word in title or lower case, depending on the words before it.
+func casedWords(words []string, casing CaseStyle, lang language.Tag) map[string]bool {
+	styles := []CaseStyle{casing}
+
+	switch casing {
+	case CaseMixed:
+		return nil
+	case CaseRandomTitle, CaseFirstUpper:
+		styles = []CaseStyle{CaseTitle, CaseLower}
+	}
+
+	set := make(map[string]bool, len(words)*len(styles))
+
+	for _, word := range words {
+		for _, style := range styles {
+			set[casedWord(word, style, lang)] = true
+		}
+	}
+
+	return set
+}
+
+// wordStats returns the cached statistics of the dictionary's words,
+// computing them on first use and again if the dictionary or the casing style was changed since.
+func (w *WordToken) wordStats() *wordStats {
+	if stats := w.stats.Load(); stats != nil && stats.dict == w.Dict && stats.casing == w.Casing {
+		return stats
+	}
+
+	dict, casing := w.Dict, w.Casing
+	stats := &wordStats{
+		dict:   dict,
+		casing: casing,
+		bits:   dict.EntropyBits(),
+		runes: sync.OnceValue(func() map[rune]bool {
+			return casedRunes(dict.Words(), dict.Language())
+		}),
+		outputs: sync.OnceValue(func() map[string]bool {
+			return casedWords(dict.Words(), casing, dict.Language())
+		}),
+	}
+
+	if casing.IsRandom() {
+		words := dict.Words()
+		total := 0.0
+
+		for _, word := range words {
+			total += casing.EntropyBits(word)
+		}
+
+		if len(words) > 0 {
+			stats.bits += total / float64(len(words))
+		} else {
+			stats.bits = 0
+		}
+	}
+
+	w.stats.Store(stats)
+
+	return stats
+}
+
+// lengthDistribution returns the probability of each word length in characters.
+func (w *WordToken) lengthDistribution() []float64 {
+	words := w.Dict.Words()
+
+	var lengths []float64
+
+	for _, word := range words {
+		length := utf8.RuneCountInString(word)
+		if length >= len(lengths) {
+			lengths = append(lengths, make([]float64, length-len(lengths)+1)...)
+		}
+
+		lengths[length] += 1 / float64(len(words))
+	}
+
+	return lengths
+}
+
+// lengthClasses returns the probability and average entropy of the words of each length.
+func (w *WordToken) lengthClasses() []lengthClass {
+	words := w.Dict.Words()
+	bits := w.Dict.EntropyBits()
+
+	var classes []lengthClass
+
+	for _, word := range words {
+		length := utf8.RuneCountInString(word)
+		if length >= len(classes) {
+			classes = append(classes, make([]lengthClass, length-len(classes)+1)...)
+		}
+
+		classes[length].probability += 1 / float64(len(words))
+		classes[length].bits += (bits + w.Casing.EntropyBits(word)) / float64(len(words))
+	}
+
+	// Turn the probability-weighted sums into averages.
+	for i := range classes {
+		if classes[i].probability > 0 {
+			classes[i].bits /= classes[i].probability
+		}
+	}
+
+	return classes
 }
 
 // Type returns a description of this token type.
 func (w *WordToken) Type() string {
+	if w.Source != "" {
+		return fmt.Sprintf("word(%s@%s)", w.Casing, w.Source)
+	}
+
 	return fmt.Sprintf("word(%s)", w.Casing)
 }
 
+// DSL returns the word token in pattern DSL syntax, e.g. `W@nouns<3-6>(exclude="[qxz]"):title`.
+func (w *WordToken) DSL() string {
+	var result strings.Builder
+
+	result.WriteString("W")
+
+	if w.Source != "" {
+		result.WriteString("@" + w.Source)
+	}
+
+	if w.MinLength > 0 || w.MaxLength > 0 {
+		fmt.Fprintf(&result, "<%s-%s>", lengthBound(w.MinLength), lengthBound(w.MaxLength))
+	}
+
+	var filters []string
+
+	for _, filter := range []struct{ key, value string }{
+		{"include", w.Include}, {"exclude", w.Exclude}, {"blocklist", w.Blocklist},
+	} {
+		if filter.value != "" {
+			filters = append(filters, filter.key+"="+strconv.Quote(filter.value))
+		}
+	}
+
+	if len(filters) > 0 {
+		result.WriteString("(" + strings.Join(filters, ", ") + ")")
+	}
+
+	if w.Casing != CaseLower {
+		result.WriteString(":" + w.Casing.String())
+	}
+
+	return result.String()
+}
+
+// lengthBound formats a word length bound, leaving unbounded (zero) values empty.
+func lengthBound(length int) string {
+	if length <= 0 {
+		return ""
+	}
+
+	return strconv.Itoa(length)
+}
+
 // DigitToken generates random digits.
 type DigitToken struct {
 	Count int // Number of digits to generate
 }
 
 // Generate produces random digits.
-func (d *DigitToken) Generate() (string, error) {
+func (d *DigitToken) Generate(source io.Reader) (string, error) {
 	if d.Count <= 0 {
 		return "", errors.New("digit count must be positive")
 	}
@@ -76,12 +276,12 @@ func (d *DigitToken) Generate() (string, error) {
 	result.Grow(d.Count)
 
 	for range d.Count {
-		digit, err := rand.Int(rand.Reader, big.NewInt(digitBase))
+		digit, err := random.Int(source, digitBase)
 		if err != nil {
 			return "", fmt.Errorf("generating random digit: %w", err)
 		}
 
-		result.WriteString(strconv.FormatInt(digit.Int64(), digitBase))
+		result.WriteString(strconv.Itoa(digit))
 	}
 
 	return result.String(), nil
@@ -105,6 +305,15 @@ func (d *DigitToken) Type() string {
 	return fmt.Sprintf("digits(%d)", d.Count)
 }
 
+// DSL returns the digit token in pattern DSL syntax, e.g. "D{4}".
+func (d *DigitToken) DSL() string {
+	if d.Count == 1 {
+		return "D"
+	}
+
+	return fmt.Sprintf("D{%d}", d.Count)
+}
+
 // SymbolToken generates random symbols from a character set.
 type SymbolToken struct {
 	Count   int    // Number of symbols to generate
@@ -115,7 +324,7 @@ type SymbolToken struct {
 const DefaultSymbolCharset = "!@#$%^&*()_+-=[]{}|;:,.<>?"
 
 // Generate produces random symbols.
-func (s *SymbolToken) Generate() (string, error) {
+func (s *SymbolToken) Generate(source io.Reader) (string, error) {
 	if s.Count <= 0 {
 		return "", errors.New("symbol count must be positive")
 	}
@@ -132,15 +341,13 @@ func (s *SymbolToken) Generate() (string, error) {
 	var result strings.Builder
 	result.Grow(s.Count)
 
-	maxIdx := big.NewInt(int64(len(charset)))
-
 	for range s.Count {
-		idx, err := rand.Int(rand.Reader, maxIdx)
+		idx, err := random.Int(source, len(charset))
 		if err != nil {
 			return "", fmt.Errorf("generating random symbol: %w", err)
 		}
 
-		result.WriteByte(charset[idx.Int64()])
+		result.WriteByte(charset[idx])
 	}
 
 	return result.String(), nil
@@ -174,13 +381,22 @@ func (s *SymbolToken) Type() string {
 	return fmt.Sprintf("symbols(%d)", s.Count)
 }
 
+// DSL returns the symbol token in pattern DSL syntax, e.g. "S{2}".
+func (s *SymbolToken) DSL() string {
+	if s.Count == 1 {
+		return "S"
+	}
+
+	return fmt.Sprintf("S{%d}", s.Count)
+}
+
 // SeparatorToken represents a fixed separator.
 type SeparatorToken struct {
 	Value string
 }
 
 // Generate returns the separator value (no randomness).
-func (s *SeparatorToken) Generate() (string, error) {
+func (s *SeparatorToken) Generate(_ io.Reader) (string, error) {
 	return s.Value, nil
 }
 
@@ -194,6 +410,36 @@ func (s *SeparatorToken) Type() string {
 	return fmt.Sprintf("sep(%q)", s.Value)
 }
 
+// DSL returns the separator in pattern DSL syntax with its value spelled out, e.g. SEP("-").
+func (s *SeparatorToken) DSL() string {
+	return "SEP(" + strconv.Quote(s.Value) + ")"
+}
+
+// LiteralToken represents fixed text written verbatim into the passphrase.
+type LiteralToken struct {
+	Value string
+}
+
+// Generate returns the literal value (no randomness).
+func (l *LiteralToken) Generate(_ io.Reader) (string, error) {
+	return l.Value, nil
+}
+
+// EntropyBits returns zero (literals add no entropy).
+func (l *LiteralToken) EntropyBits() float64 {
+	return 0
+}
+
+// Type returns a description of this token type.
+func (l *LiteralToken) Type() string {
+	return fmt.Sprintf("literal(%q)", l.Value)
+}
+
+// DSL returns the literal in pattern DSL syntax, e.g. "corp-" in double quotes.
+func (l *LiteralToken) DSL() string {
+	return strconv.Quote(l.Value)
+}
+
 // CaseStyle represents different casing styles.
 type CaseStyle int
 
@@ -206,6 +452,14 @@ const (
 	CaseTitle
 	// CaseMixed represents randomly mixed case style.
 	CaseMixed
+	// CaseAlternating alternates lower and upper case letters (aLtErNaTe).
+	CaseAlternating
+	// CaseRandomTitle randomly picks title or lower case for each word.
+	CaseRandomTitle
+	// CaseFirstUpper title-cases the first word written for a passphrase and lowercases the others.
+	CaseFirstUpper
+	// CaseInvertedTitle lowercases the first letter and uppercases the rest (wORD).
+	CaseInvertedTitle
 )
 
 func (c CaseStyle) String() string {
@@ -218,61 +472,209 @@ func (c CaseStyle) String() string {
 		return "title"
 	case CaseMixed:
 		return "mixed"
+	case CaseAlternating:
+		return "alternating"
+	case CaseRandomTitle:
+		return "random-title"
+	case CaseFirstUpper:
+		return "first-upper"
+	case CaseInvertedTitle:
+		return "inverted-title"
 	default:
 		return "unknown"
 	}
 }
 
-// EntropyBits returns additional entropy bits contributed by this casing style.
-func (c CaseStyle) EntropyBits() float64 {
+// IsRandom reports whether the style adds randomness, so that its entropy depends on the word.
+func (c CaseStyle) IsRandom() bool {
+	return c == CaseMixed || c == CaseRandomTitle
+}
+
+// EntropyBits returns the entropy this casing style adds to word.
+func (c CaseStyle) EntropyBits(word string) float64 {
 	switch c {
-	case CaseLower, CaseUpper, CaseTitle:
+	case CaseLower, CaseUpper, CaseTitle, CaseAlternating, CaseFirstUpper, CaseInvertedTitle:
 		// Fixed casing adds no additional entropy
+		return 0
+	case CaseRandomTitle:
+		// One coin toss, visible only if the first letter has distinct cases
+		for _, r := range word {
+			if unicode.IsLetter(r) {
+				if unicode.ToUpper(r) != unicode.ToLower(r) {
+					return 1
+				}
+
+				return 0
+			}
+		}
+
 		return 0
 	case CaseMixed:
-		// Mixed case adds entropy by randomizing case per character
-		// This is a simplified calculation
-		return 1.0
+		letters, cased := 0, 0
+		firstCased := false
+
+		for _, r := range word {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+
+			isCased := unicode.ToUpper(r) != unicode.ToLower(r)
+			if letters == 0 {
+				firstCased = isCased
+			}
+
+			letters++
+
+			if isCased {
+				cased++
+			}
+		}
+
+		return mixedCaseBits(letters, cased, firstCased)
 	default:
 		// Fixed casing adds no additional entropy
 		return 0
 	}
 }
 
-// ApplyCasing applies the specified casing style to a word.
-func ApplyCasing(word string, style CaseStyle) (string, error) {
+// mixedCaseBits returns the exact entropy of applyMixedCase for a word with the given number of
+// letters, of which cased have distinct upper and lower forms.
+//
+// Every letter is flipped by a coin toss, so each case pattern of the cased letters has
+// probability 2^-cased. When no letter was flipped (probability 2^-letters), the first letter
+// is uppercased instead: if it is cased, the all-lowercase pattern loses that probability
+// to the "only the first letter uppercase" pattern. For an all-cased word this gives
+// letters - 2^(1-letters) bits, e.g. 0 bits for a one-letter word and 3.875 bits for four letters.
+func mixedCaseBits(letters, cased int, firstCased bool) float64 {
+	if cased == 0 {
+		return 0
+	}
+
+	uniform := math.Exp2(-float64(cased))
+	bits := float64(cased)
+
+	if !firstCased {
+		return bits
+	}
+
+	forced := math.Exp2(-float64(letters))
+	allLower, firstOnly := uniform-forced, uniform+forced
+
+	// Replace the uniform terms of the two affected patterns by their actual probabilities.
+	return bits - 2*uniform*bits + surprisal(allLower) + surprisal(firstOnly)
+}
+
+// surprisal returns -p*log2(p), the contribution of an outcome with probability p to the entropy.
+func surprisal(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+
+	return -p * math.Log2(p)
+}
+
+// ApplyCasing applies the specified casing style to a word using the casing
+// rules of lang (e.g. Turkish dotted i, Dutch "IJ", German "ß").
+// Random styles draw their randomness from source.
+func ApplyCasing(word string, style CaseStyle, lang language.Tag, source io.Reader) (string, error) {
 	switch style {
 	case CaseLower:
-		return strings.ToLower(word), nil
+		return cases.Lower(lang).String(word), nil
 	case CaseUpper:
-		return strings.ToUpper(word), nil
+		return cases.Upper(lang).String(word), nil
 	case CaseTitle:
-		return cases.Title(language.English).String(strings.ToLower(word)), nil
+		return cases.Title(lang).String(cases.Lower(lang).String(word)), nil
 	case CaseMixed:
-		return applyMixedCase(word)
+		return applyMixedCase(word, lang, source)
+	case CaseAlternating:
+		return applyAlternatingCase(word, lang), nil
+	case CaseRandomTitle:
+		bit, err := random.Int(source, binaryChoiceRange)
+		if err != nil {
+			return "", fmt.Errorf("generating random bit for casing: %w", err)
+		}
+
+		if bit == 1 {
+			return ApplyCasing(word, CaseTitle, lang, source)
+		}
+
+		return ApplyCasing(word, CaseLower, lang, source)
+	case CaseFirstUpper:
+		// Word tokens pick title or lower case as they are written; a lone word is the first one.
+		return ApplyCasing(word, CaseTitle, lang, source)
+	case CaseInvertedTitle:
+		_, size := utf8.DecodeRuneInString(word)
+
+		return cases.Lower(lang).String(word[:size]) + cases.Upper(lang).String(word[size:]), nil
 	default:
 		return word, nil
 	}
 }
 
+// applyAlternatingCase lowercases the first letter and then alternates upper and lower case.
+func applyAlternatingCase(word string, lang language.Tag) string {
+	runes := []rune(word)
+	toUpper, toLower := unicode.ToUpper, unicode.ToLower
+
+	if special := letterCase(lang); special != nil {
+		toUpper, toLower = special.ToUpper, special.ToLower
+	}
+
+	letters := 0
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		if letters%2 == 0 {
+			runes[i] = toLower(r)
+		} else {
+			runes[i] = toUpper(r)
+		}
+
+		letters++
+	}
+
+	return string(runes)
+}
+
+// letterCase returns the special casing rules for lang, if any.
+func letterCase(lang language.Tag) unicode.SpecialCase {
+	base, _ := lang.Base()
+
+	switch base.String() {
+	case "tr", "az":
+		return unicode.TurkishCase
+	default:
+		return nil
+	}
+}
+
 // applyMixedCase applies random casing to each alphabetic character.
-func applyMixedCase(word string) (string, error) {
+func applyMixedCase(word string, lang language.Tag, source io.Reader) (string, error) {
 	runes := []rune(word)
 	changes := 0
+	special := letterCase(lang)
+
+	toUpper, toLower := unicode.ToUpper, unicode.ToLower
+	if special != nil {
+		toUpper, toLower = special.ToUpper, special.ToLower
+	}
 
 	// First pass: randomize case
 	for i, r := range runes { //nolint:varnamelen // i is standard loop var, r is standard for rune
 		if unicode.IsLetter(r) {
-			bit, err := rand.Int(rand.Reader, big.NewInt(binaryChoiceRange))
+			bit, err := random.Int(source, binaryChoiceRange)
 			if err != nil {
 				return "", fmt.Errorf("generating random bit for casing: %w", err)
 			}
 
-			if bit.Int64() == 1 {
-				runes[i] = unicode.ToUpper(r)
+			if bit == 1 {
+				runes[i] = toUpper(r)
 				changes++
 			} else {
-				runes[i] = unicode.ToLower(r)
+				runes[i] = toLower(r)
 			}
 		}
 	}
@@ -282,7 +684,7 @@ func applyMixedCase(word string) (string, error) {
 		// Find first letter and make it uppercase
 		for i, r := range runes {
 			if unicode.IsLetter(r) {
-				runes[i] = unicode.ToUpper(r)
+				runes[i] = toUpper(r)
 
 				break
 			}
@@ -303,6 +705,14 @@ func ParseCaseStyle(str string) (CaseStyle, error) {
 		return CaseTitle, nil
 	case "mixed":
 		return CaseMixed, nil
+	case "alternating":
+		return CaseAlternating, nil
+	case "random-title":
+		return CaseRandomTitle, nil
+	case "first-upper":
+		return CaseFirstUpper, nil
+	case "inverted-title":
+		return CaseInvertedTitle, nil
 	default:
 		return CaseLower, fmt.Errorf("unknown case style: %q", str)
 	}
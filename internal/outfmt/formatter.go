@@ -5,6 +5,8 @@ import (
 	"io"
 	"math"
 
+	"golang.org/x/text/language"
+
 	"github.com/idelchi/pwgen/internal/dictionary"
 	"github.com/idelchi/pwgen/internal/generate"
 )
@@ -19,6 +21,15 @@ type Formatter interface {
 
 	// FormatDictionaries formats dictionary information.
 	FormatDictionaries(dicts []DictionaryInfo) error
+
+	// FormatValidation formats a dictionary validation report.
+	FormatValidation(report dictionary.ValidationReport) error
+
+	// FormatExplanation formats the per-token entropy breakdown of a pattern.
+	FormatExplanation(explanation generate.Explanation) error
+
+	// FormatShares formats the shares of a split passphrase.
+	FormatShares(shares SharesInfo) error
 }
 
 // DictionaryInfo represents information about a dictionary for display.
@@ -27,10 +38,21 @@ type DictionaryInfo struct {
 	Description string  `json:"description"`
 	WordCount   int     `json:"wordCount"`
 	EntropyBits float64 `json:"entropyBits"`
+	Language    string  `json:"language,omitempty"`
 	Path        string  `json:"path,omitempty"`
 	Type        string  `json:"type"`
 }
 
+// SharesInfo represents the shares of a split passphrase for display.
+type SharesInfo struct {
+	// Passphrase and Entropy are set when the passphrase was generated to be split.
+	Passphrase string   `json:"passphrase,omitempty"`
+	Entropy    float64  `json:"entropy,omitempty"`
+	Dictionary string   `json:"dictionary"`
+	Threshold  int      `json:"threshold"`
+	Shares     []string `json:"shares"`
+}
+
 // NewFormatter creates a new formatter based on the specified type.
 //
 //nolint:ireturn // Formatter interface is required for polymorphism in output formatting
@@ -60,6 +82,7 @@ func DictionaryInfoFromDict(dict dictionary.Dictionary, dictType string) Diction
 		Description: dict.Name() + " dictionary",
 		WordCount:   dict.Size(),
 		EntropyBits: dict.EntropyBits(),
+		Language:    languageName(dict.Language()),
 		Type:        dictType,
 	}
 }
@@ -71,6 +94,7 @@ func DictionaryInfoFromBuiltin(info dictionary.BuiltinDict) DictionaryInfo {
 		Description: info.Description,
 		WordCount:   info.WordCount,
 		EntropyBits: calculateEntropyBits(info.WordCount),
+		Language:    languageName(info.Language),
 		Type:        "builtin",
 	}
 }
@@ -82,6 +106,7 @@ func DictionaryInfoFromFile(path string, dict dictionary.Dictionary) DictionaryI
 		Description: "External dictionary from " + path,
 		WordCount:   dict.Size(),
 		EntropyBits: dict.EntropyBits(),
+		Language:    languageName(dict.Language()),
 		Path:        path,
 		Type:        "file",
 	}
@@ -95,3 +120,12 @@ func calculateEntropyBits(wordCount int) float64 {
 	// log2(wordCount)
 	return math.Log2(float64(wordCount))
 }
+
+// languageName returns the BCP 47 tag of a language, or "" if it is unknown.
+func languageName(lang language.Tag) string {
+	if lang == language.Und {
+		return ""
+	}
+
+	return lang.String()
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/idelchi/pwgen/internal/dictionary"
 	"github.com/idelchi/pwgen/internal/generate"
 )
 
@@ -113,3 +114,87 @@ func (f *JSONFormatter) FormatDictionaries(dicts []DictionaryInfo) error {
 
 	return err
 }
+
+// FormatValidation formats a dictionary validation report as JSON.
+func (f *JSONFormatter) FormatValidation(report dictionary.ValidationReport) error {
+	var (
+		output []byte
+		err    error
+	)
+
+	if f.pretty {
+		output, err = json.MarshalIndent(report, "", "  ")
+	} else {
+		output, err = json.Marshal(report)
+	}
+
+	if err != nil {
+		return fmt.Errorf("marshaling validation JSON: %w", err)
+	}
+
+	_, err = f.writer.Write(output)
+	if err != nil {
+		return fmt.Errorf("writing validation JSON: %w", err)
+	}
+
+	// Add newline
+	_, err = f.writer.Write([]byte("\n"))
+
+	return err
+}
+
+// FormatExplanation formats a pattern explanation as JSON.
+func (f *JSONFormatter) FormatExplanation(explanation generate.Explanation) error {
+	var (
+		output []byte
+		err    error
+	)
+
+	if f.pretty {
+		output, err = json.MarshalIndent(explanation, "", "  ")
+	} else {
+		output, err = json.Marshal(explanation)
+	}
+
+	if err != nil {
+		return fmt.Errorf("marshaling explanation JSON: %w", err)
+	}
+
+	_, err = f.writer.Write(output)
+	if err != nil {
+		return fmt.Errorf("writing explanation JSON: %w", err)
+	}
+
+	// Add newline
+	_, err = f.writer.Write([]byte("\n"))
+
+	return err
+}
+
+// FormatShares formats the shares of a split passphrase as JSON.
+func (f *JSONFormatter) FormatShares(shares SharesInfo) error {
+	var (
+		output []byte
+		err    error
+	)
+
+	if f.pretty {
+		output, err = json.MarshalIndent(shares, "", "  ")
+	} else {
+		output, err = json.Marshal(shares)
+	}
+
+	if err != nil {
+		return fmt.Errorf("marshaling shares JSON: %w", err)
+	}
+
+	_, err = f.writer.Write(output)
+	if err != nil {
+		return fmt.Errorf("writing shares JSON: %w", err)
+	}
+
+	// Add newline
+	_, err = f.writer.Write([]byte("\n"))
+
+	return err
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/idelchi/pwgen/internal/dictionary"
 	"github.com/idelchi/pwgen/internal/generate"
 )
 
@@ -57,6 +59,12 @@ func (f *TextFormatter) FormatAnalysis(analysis generate.AnalysisResult) error {
 	fmt.Fprintf(f.writer, "Character sets: %s\n", strings.Join(analysis.Charsets, ", "))
 	fmt.Fprintf(f.writer, "Charset size: %d\n", analysis.CharsetSize)
 	fmt.Fprintf(f.writer, "Entropy: %.1f bits\n", analysis.Entropy)
+
+	if recognized := analysis.Recognized; recognized != nil {
+		fmt.Fprintf(f.writer, "Pattern entropy: %.1f bits (pwgen pattern %s with %s)\n",
+			recognized.Entropy, recognized.Pattern, recognized.Dictionary)
+	}
+
 	fmt.Fprintf(f.writer, "Strength: %s\n", f.colorizeStrength(analysis.Strength))
 	fmt.Fprintf(f.writer, "Estimated crack time: %s\n", analysis.CrackTime)
 
@@ -64,12 +72,30 @@ func (f *TextFormatter) FormatAnalysis(analysis generate.AnalysisResult) error {
 		fmt.Fprintf(f.writer, "Word-based structure detected: ~%d words\n", analysis.EstimatedWords)
 	}
 
+	if found := analysis.Breach; found != nil {
+		if found.Breached {
+			fmt.Fprintf(f.writer, "Breach check: %s (seen %d times in %s)\n",
+				f.colorizePolicyStatus(false), found.Count, found.Database)
+		} else {
+			fmt.Fprintf(f.writer, "Breach check: %s (not in %s)\n", f.colorizePolicyStatus(true), found.Database)
+		}
+	}
+
+	if mnemonic := analysis.Mnemonic; mnemonic != nil {
+		if mnemonic.Valid {
+			fmt.Fprintf(f.writer, "BIP39 mnemonic: %s (%d words, %d bits of entropy)\n",
+				f.colorizePolicyStatus(true), mnemonic.Words, mnemonic.EntropyBits)
+		} else {
+			fmt.Fprintf(f.writer, "BIP39 mnemonic: %s (%s)\n", f.colorizePolicyStatus(false), mnemonic.Error)
+		}
+	}
+
 	if len(analysis.Patterns) > 0 {
 		fmt.Fprintf(f.writer, "\nDetected patterns:\n")
 
 		for _, pattern := range analysis.Patterns {
-			fmt.Fprintf(f.writer, "  - %s: %s (-%0.1f bits)\n",
-				pattern.Type, pattern.Description, pattern.Penalty)
+			fmt.Fprintf(f.writer, "  - %s: %s (%0.1f bits)\n",
+				pattern.Type, pattern.Description, pattern.Entropy)
 		}
 	}
 
@@ -87,6 +113,10 @@ func (f *TextFormatter) FormatDictionaries(dicts []DictionaryInfo) error {
 		fmt.Fprintf(f.writer, "Word count: %d\n", dict.WordCount)
 		fmt.Fprintf(f.writer, "Entropy per word: %.1f bits\n", dict.EntropyBits)
 
+		if dict.Language != "" {
+			fmt.Fprintf(f.writer, "Language: %s\n", dict.Language)
+		}
+
 		if dict.Path != "" {
 			fmt.Fprintf(f.writer, "Path: %s\n", dict.Path)
 		}
@@ -98,9 +128,106 @@ func (f *TextFormatter) FormatDictionaries(dicts []DictionaryInfo) error {
 	return nil
 }
 
-// formatResultSimple outputs just the passphrase.
+// FormatValidation formats a dictionary validation report as plain text.
+func (f *TextFormatter) FormatValidation(report dictionary.ValidationReport) error {
+	fmt.Fprintf(f.writer, "Dictionary Validation\n")
+	fmt.Fprintf(f.writer, "=====================\n\n")
+
+	fmt.Fprintf(f.writer, "Dictionary: %s\n", report.Name)
+	fmt.Fprintf(f.writer, "Words: %d\n", report.Words)
+	fmt.Fprintf(f.writer, "Distinct words: %d\n", report.DistinctWords)
+	fmt.Fprintf(f.writer, "Entropy per word: %.1f bits\n", report.EntropyBits)
+	fmt.Fprintf(f.writer, "Prefix-free: %t\n", report.PrefixFree)
+
+	if report.UniquePrefixLength > 0 {
+		fmt.Fprintf(f.writer, "Unique prefix length: %d\n", report.UniquePrefixLength)
+	}
+
+	fmt.Fprintf(f.writer, "Result: %s\n", f.colorizePolicyStatus(report.Valid))
+
+	var issues []string
+
+	for _, word := range report.Duplicates {
+		issues = append(issues, fmt.Sprintf("duplicate: %q", word))
+	}
+
+	for _, group := range report.CaseCollisions {
+		issues = append(issues, "case collision: "+strings.Join(group, ", "))
+	}
+
+	if report.EmptyWords > 0 {
+		issues = append(issues, fmt.Sprintf("empty words: %d", report.EmptyWords))
+	}
+
+	for _, word := range report.WhitespaceWords {
+		issues = append(issues, fmt.Sprintf("contains whitespace: %q", word))
+	}
+
+	for _, conflict := range report.PrefixConflicts {
+		issues = append(issues, fmt.Sprintf("prefix: %q is a prefix of %q", conflict.Prefix, conflict.Word))
+	}
+
+	if len(issues) > 0 {
+		fmt.Fprintf(f.writer, "\nIssues:\n")
+
+		for _, issue := range issues {
+			fmt.Fprintf(f.writer, "  - %s\n", issue)
+		}
+	}
+
+	return nil
+}
+
+// FormatExplanation formats a pattern explanation as plain text.
+func (f *TextFormatter) FormatExplanation(explanation generate.Explanation) error {
+	fmt.Fprintf(f.writer, "Pattern Explanation\n")
+	fmt.Fprintf(f.writer, "===================\n\n")
+
+	fmt.Fprintf(f.writer, "Pattern: %s\n", explanation.Pattern)
+	fmt.Fprintf(f.writer, "Entropy: %.1f bits\n\n", explanation.Entropy)
+
+	dslWidth, typeWidth := 0, 0
+
+	for _, token := range explanation.Tokens {
+		dslWidth = max(dslWidth, utf8.RuneCountInString(token.DSL))
+		typeWidth = max(typeWidth, utf8.RuneCountInString(token.Type))
+	}
+
+	for _, token := range explanation.Tokens {
+		fmt.Fprintf(f.writer, "  %-*s  %-*s  %5.1f bits\n", dslWidth, token.DSL, typeWidth, token.Type, token.Entropy)
+	}
+
+	return nil
+}
+
+// FormatShares formats the shares of a split passphrase as plain text, one share per line.
+func (f *TextFormatter) FormatShares(shares SharesInfo) error {
+	if shares.Passphrase != "" {
+		fmt.Fprintf(f.writer, "Passphrase: %s\n", shares.Passphrase)
+		fmt.Fprintf(f.writer, "Entropy: %.1f bits\n\n", shares.Entropy)
+	}
+
+	fmt.Fprintf(f.writer, "Any %d of these %d shares reconstruct the passphrase (words from %s):\n\n",
+		shares.Threshold, len(shares.Shares), shares.Dictionary)
+
+	for i, share := range shares.Shares {
+		fmt.Fprintf(f.writer, "Share %d/%d: %s\n", i+1, len(shares.Shares), share)
+	}
+
+	return nil
+}
+
+// formatResultSimple outputs just the passphrase, followed by the dice rolls of its words if known.
 func (f *TextFormatter) formatResultSimple(result generate.Result) error {
-	_, err := fmt.Fprintln(f.writer, result.Passphrase)
+	if _, err := fmt.Fprintln(f.writer, result.Passphrase); err != nil {
+		return err
+	}
+
+	if len(result.Rolls) == 0 {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(f.writer, "Rolls: %s\n", strings.Join(result.Rolls, " "))
 
 	return err
 }
@@ -135,6 +262,12 @@ func (f *TextFormatter) formatResultVerbose(result generate.Result) error {
 		return err
 	}
 
+	if len(result.Rolls) > 0 {
+		if _, err := fmt.Fprintf(f.writer, "Dice rolls: %s\n", strings.Join(result.Rolls, " ")); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,82 @@
+// Package random draws uniformly distributed values from a source of random bytes.
+//
+// A source of randomness is any io.Reader: crypto/rand.Reader by default, a hardware RNG device,
+// a derived key stream or a seeded source. The algorithms are fixed, so a deterministic source
+// always yields the same values.
+package random
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand/v2"
+)
+
+// floatBits is the precision of the fractions returned by Float64.
+const floatBits = 53
+
+// Int returns a uniformly distributed integer in [0, n), read from source.
+//
+// It reads 8 bytes as a big-endian integer and retries while the value falls in the
+// incomplete last multiple of n, so every result is equally likely. For n == 1 nothing is read.
+func Int(source io.Reader, n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("random range must be positive, got %d", n)
+	}
+
+	if n == 1 {
+		return 0, nil
+	}
+
+	bound := uint64(n)
+	// Values at or above limit would make the smaller results more likely.
+	limit := math.MaxUint64 - (math.MaxUint64%bound+1)%bound
+
+	for {
+		value, err := uint64From(source)
+		if err != nil {
+			return 0, err
+		}
+
+		if value <= limit {
+			return int(value % bound), nil //nolint:gosec // value % bound < n fits in an int
+		}
+	}
+}
+
+// Float64 returns a uniformly distributed fraction in [0, 1) with 53 bits of precision, read from source.
+func Float64(source io.Reader) (float64, error) {
+	value, err := uint64From(source)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(value>>(64-floatBits)) / (1 << floatBits), nil
+}
+
+// NewSeeded returns a deterministic source of randomness seeded with seed, for reproducible output in tests.
+// Anyone who knows the seed can reproduce its values, so it must never be used for real passphrases.
+func NewSeeded(seed uint64) io.Reader {
+	var key [32]byte
+
+	binary.BigEndian.PutUint64(key[:], seed)
+
+	return mathrand.NewChaCha8(key)
+}
+
+// uint64From reads a big-endian 64-bit integer from source.
+func uint64From(source io.Reader) (uint64, error) {
+	if source == nil {
+		return 0, errors.New("no source of randomness")
+	}
+
+	var buf [8]byte
+
+	if _, err := io.ReadFull(source, buf[:]); err != nil {
+		return 0, fmt.Errorf("reading random bytes: %w", err)
+	}
+
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
@@ -0,0 +1,241 @@
+// Package shamir splits secrets into shares with Shamir's secret sharing over GF(256),
+// so that any threshold of the shares reconstructs the secret and fewer reveal nothing about it.
+//
+// Each byte of the secret is the constant term of its own random polynomial of degree threshold-1,
+// and a share holds the polynomials evaluated at the share's index. The field is GF(2^8) with the
+// AES polynomial x^8 + x^4 + x^3 + x + 1.
+//
+// Secrets and shares are held in safety.SecureBuffer, and intermediate values are wiped after use.
+package shamir
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/idelchi/pwgen/internal/safety"
+)
+
+const (
+	// MinThreshold is the smallest number of shares that can be required to reconstruct a secret.
+	MinThreshold = 2
+	// MaxShares is the largest number of shares, one per non-zero element of GF(256).
+	MaxShares = 255
+
+	// idBytes is the size of the random identifier shared by the shares of a split.
+	idBytes = 2
+)
+
+// Share is one share of a split secret.
+type Share struct {
+	// ID is random and the same for all shares of a split, so that shares of different splits are not mixed.
+	ID uint16
+	// Threshold is the number of shares needed to reconstruct the secret.
+	Threshold int
+	// Index is the share's x coordinate, from 1 to 255.
+	Index int
+	// Value holds the polynomials evaluated at Index, one byte per byte of the secret.
+	Value *safety.SecureBuffer
+}
+
+// Wipe clears the share's value.
+func (s Share) Wipe() {
+	if s.Value != nil {
+		s.Value.Wipe()
+	}
+}
+
+// Split splits secret into shares, any threshold of which reconstruct it,
+// drawing the polynomials' coefficients and the split's ID from source.
+func Split(secret *safety.SecureBuffer, shares, threshold int, source io.Reader) ([]Share, error) {
+	switch {
+	case secret.Len() == 0:
+		return nil, errors.New("nothing to split")
+	case threshold < MinThreshold:
+		return nil, fmt.Errorf("threshold must be at least %d, not %d", MinThreshold, threshold)
+	case shares < threshold:
+		return nil, fmt.Errorf("cannot require %d shares out of %d", threshold, shares)
+	case shares > MaxShares:
+		return nil, fmt.Errorf("at most %d shares are supported, not %d", MaxShares, shares)
+	case source == nil:
+		return nil, errors.New("no source of randomness")
+	}
+
+	plain := secret.Bytes()
+	defer safety.WipeBytes(plain)
+
+	// coefficients[j*threshold:(j+1)*threshold] is the polynomial of byte j, constant term first
+	coefficients := make([]byte, len(plain)*threshold)
+	defer safety.WipeBytes(coefficients)
+
+	for j, b := range plain {
+		polynomial := coefficients[j*threshold : (j+1)*threshold]
+		polynomial[0] = b
+
+		if _, err := io.ReadFull(source, polynomial[1:]); err != nil {
+			return nil, fmt.Errorf("reading random bytes: %w", err)
+		}
+	}
+
+	id := make([]byte, idBytes)
+	if _, err := io.ReadFull(source, id); err != nil {
+		return nil, fmt.Errorf("reading random bytes: %w", err)
+	}
+
+	values := make([]byte, len(plain))
+	defer safety.WipeBytes(values)
+
+	result := make([]Share, shares)
+
+	for i := range result {
+		x := byte(i + 1) //nolint:gosec // at most MaxShares
+
+		for j := range plain {
+			values[j] = evaluate(coefficients[j*threshold:(j+1)*threshold], x)
+		}
+
+		result[i] = Share{
+			ID:        uint16(id[0])<<8 | uint16(id[1]),
+			Threshold: threshold,
+			Index:     int(x),
+			Value:     safety.NewSecureBuffer(len(values)),
+		}
+		result[i].Value.Write(values)
+	}
+
+	return result, nil
+}
+
+// Combine reconstructs the secret from at least a threshold of the shares of one split.
+// The caller should wipe the returned buffer.
+func Combine(shares []Share) (*safety.SecureBuffer, error) {
+	if err := checkShares(shares); err != nil {
+		return nil, err
+	}
+
+	// The first threshold shares determine the polynomials
+	shares = shares[:shares[0].Threshold]
+
+	xs := make([]byte, len(shares))
+	ys := make([][]byte, len(shares))
+
+	defer func() {
+		for _, y := range ys {
+			safety.WipeBytes(y)
+		}
+	}()
+
+	for i, share := range shares {
+		xs[i] = byte(share.Index) //nolint:gosec // checked to be at most MaxShares
+		ys[i] = share.Value.Bytes()
+	}
+
+	plain := make([]byte, len(ys[0]))
+	defer safety.WipeBytes(plain)
+
+	// Lagrange interpolation at x = 0: the sum of y_i * prod_{m != i} x_m / (x_m - x_i).
+	// Subtraction is addition (XOR) in GF(256).
+	for i := range xs {
+		basis := byte(1)
+
+		for m := range xs {
+			if m != i {
+				basis = mul(basis, div(xs[m], xs[m]^xs[i]))
+			}
+		}
+
+		for j := range plain {
+			plain[j] ^= mul(ys[i][j], basis)
+		}
+	}
+
+	secret := safety.NewSecureBuffer(len(plain))
+	secret.Write(plain)
+
+	return secret, nil
+}
+
+// checkShares checks that the shares belong to one split and are enough to reconstruct it.
+func checkShares(shares []Share) error {
+	if len(shares) == 0 {
+		return errors.New("no shares given")
+	}
+
+	for _, share := range shares {
+		if err := share.check(); err != nil {
+			return err
+		}
+	}
+
+	first := shares[0]
+	seen := make(map[int]bool, len(shares))
+
+	for _, share := range shares {
+		switch {
+		case share.ID != first.ID:
+			return fmt.Errorf("share %d is from a different split (ID %04x, not %04x)", share.Index, share.ID, first.ID)
+		case share.Threshold != first.Threshold || share.Value.Len() != first.Value.Len():
+			return fmt.Errorf("share %d does not match share %d of the same split", share.Index, first.Index)
+		case seen[share.Index]:
+			return fmt.Errorf("share %d is given twice", share.Index)
+		}
+
+		seen[share.Index] = true
+	}
+
+	if len(shares) < first.Threshold {
+		return fmt.Errorf("%d shares are needed, but only %d were given", first.Threshold, len(shares))
+	}
+
+	return nil
+}
+
+// check checks that the share on its own could be part of a split.
+func (s Share) check() error {
+	switch {
+	case s.Index < 1 || s.Index > MaxShares:
+		return fmt.Errorf("share index %d is out of range", s.Index)
+	case s.Threshold < MinThreshold || s.Threshold > MaxShares:
+		return fmt.Errorf("share %d has an invalid threshold of %d", s.Index, s.Threshold)
+	case s.Value == nil || s.Value.Len() == 0:
+		return fmt.Errorf("share %d holds no value", s.Index)
+	default:
+		return nil
+	}
+}
+
+// evaluate evaluates the polynomial with the given coefficients, constant term first, at x.
+func evaluate(coefficients []byte, x byte) byte {
+	var y byte
+
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = mul(y, x) ^ coefficients[i]
+	}
+
+	return y
+}
+
+// mul multiplies in GF(256), in constant time.
+func mul(a, b byte) byte {
+	var product byte
+
+	for range 8 {
+		product ^= a & -(b & 1)
+		a = a<<1 ^ (0x1b & -(a >> 7))
+		b >>= 1
+	}
+
+	return product
+}
+
+// div divides in GF(256); b must not be zero.
+func div(a, b byte) byte {
+	// b^254 is the inverse of b, as b^255 = 1
+	inverse := byte(1)
+
+	for range 254 {
+		inverse = mul(inverse, b)
+	}
+
+	return mul(a, inverse)
+}
@@ -0,0 +1,159 @@
+package shamir
+
+import (
+	"testing"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+	"github.com/idelchi/pwgen/internal/random"
+	"github.com/idelchi/pwgen/internal/safety"
+)
+
+const testSecret = "correct-horse-battery-staple"
+
+// testShares splits testSecret into 5 shares with a threshold of 3.
+func testShares(t *testing.T) []Share {
+	t.Helper()
+
+	secret := safety.NewSecureBuffer(len(testSecret))
+	secret.Write([]byte(testSecret))
+
+	shares, err := Split(secret, 5, 3, random.NewSeeded(1))
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+
+	return shares
+}
+
+// value returns a share value holding data.
+func value(data ...byte) *safety.SecureBuffer {
+	buffer := safety.NewSecureBuffer(len(data))
+	buffer.Write(data)
+
+	return buffer
+}
+
+func TestSplitCombine(t *testing.T) {
+	t.Parallel()
+
+	shares := testShares(t)
+	dict := dictionary.EFF()
+
+	// Any three shares, written as words and parsed back, reconstruct the secret.
+	var parsed []Share
+
+	for _, share := range []Share{shares[4], shares[0], shares[2]} {
+		words, err := share.Words(dict)
+		if err != nil {
+			t.Fatalf("Words() error = %v", err)
+		}
+
+		share, err := ParseShare(words, dict)
+		if err != nil {
+			t.Fatalf("ParseShare() error = %v", err)
+		}
+
+		parsed = append(parsed, share)
+	}
+
+	secret, err := Combine(parsed)
+	if err != nil {
+		t.Fatalf("Combine() error = %v", err)
+	}
+
+	if got := string(secret.Bytes()); got != testSecret {
+		t.Errorf("Combine() = %q, want %q", got, testSecret)
+	}
+}
+
+func TestCombineRejectsInvalidShares(t *testing.T) {
+	t.Parallel()
+
+	shares := testShares(t)
+
+	tests := []struct {
+		name   string
+		shares []Share
+	}{
+		{name: "no shares", shares: nil},
+		{name: "too few shares", shares: shares[:2]},
+		{name: "share given twice", shares: []Share{shares[0], shares[1], shares[0]}},
+		{name: "zero threshold", shares: []Share{{ID: 1, Threshold: 0, Index: 1, Value: value(1)}}},
+		{
+			name: "threshold of one",
+			shares: []Share{
+				{ID: 1, Threshold: 1, Index: 1, Value: value(1)},
+				{ID: 1, Threshold: 1, Index: 2, Value: value(2)},
+			},
+		},
+		{
+			name: "zero index",
+			shares: []Share{
+				{ID: 1, Threshold: 2, Index: 0, Value: value(1)},
+				{ID: 1, Threshold: 2, Index: 1, Value: value(2)},
+			},
+		},
+		{
+			name: "empty value",
+			shares: []Share{
+				{ID: 1, Threshold: 2, Index: 1, Value: value()},
+				{ID: 1, Threshold: 2, Index: 2, Value: value()},
+			},
+		},
+		{
+			name: "missing value",
+			shares: []Share{
+				{ID: 1, Threshold: 2, Index: 1},
+				{ID: 1, Threshold: 2, Index: 2},
+			},
+		},
+		{
+			name: "different splits",
+			shares: []Share{
+				{ID: 1, Threshold: 2, Index: 1, Value: value(1)},
+				{ID: 2, Threshold: 2, Index: 2, Value: value(2)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := Combine(test.shares); err == nil {
+				t.Error("Combine() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestParseShareRejectsInvalidShares(t *testing.T) {
+	t.Parallel()
+
+	dict := dictionary.EFF()
+
+	tests := []struct {
+		name  string
+		share Share
+	}{
+		{name: "zero threshold", share: Share{ID: 1, Threshold: 0, Index: 1, Value: value(1)}},
+		{name: "threshold of one", share: Share{ID: 1, Threshold: 1, Index: 1, Value: value(1)}},
+		{name: "zero index", share: Share{ID: 1, Threshold: 2, Index: 0, Value: value(1)}},
+		{name: "empty value", share: Share{ID: 1, Threshold: 2, Index: 1, Value: value()}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			words, err := test.share.Words(dict)
+			if err != nil {
+				t.Fatalf("Words() error = %v", err)
+			}
+
+			if _, err := ParseShare(words, dict); err == nil {
+				t.Error("ParseShare() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package shamir
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+	"github.com/idelchi/pwgen/internal/safety"
+)
+
+const (
+	// formatVersion is the version of the encoded share layout.
+	formatVersion = 1
+
+	// headerBytes is the size of the version, ID, threshold and index that precede a share's value.
+	headerBytes = 5
+	// checksumBytes is the size of the checksum that follows a share's value.
+	checksumBytes = 2
+)
+
+// Words encodes the share as words of dict, so it can be written down like a passphrase.
+//
+// The encoded bytes are the format version, the split's ID (2 bytes, big-endian), the threshold,
+// the index, the value and the first 2 bytes of the SHA-256 of everything before them, which catches
+// most mistyped or missing words. See dictionary.EncodeBytes for how they become words.
+func (s Share) Words(dict dictionary.Dictionary) ([]string, error) {
+	payload := safety.NewSecureBuffer(headerBytes + s.Value.Len() + checksumBytes)
+	defer payload.Wipe()
+
+	payload.Write([]byte{
+		formatVersion,
+		byte(s.ID >> 8), byte(s.ID), //nolint:gosec // split into bytes
+		byte(s.Threshold), byte(s.Index), //nolint:gosec // at most MaxShares
+	})
+
+	value := s.Value.Bytes()
+	defer safety.WipeBytes(value)
+
+	payload.Write(value)
+
+	data := payload.Bytes()
+	defer safety.WipeBytes(data)
+
+	sum := sha256.Sum256(data)
+	payload.Write(sum[:checksumBytes])
+
+	encoded := payload.Bytes()
+	defer safety.WipeBytes(encoded)
+
+	return dictionary.EncodeBytes(dict, encoded)
+}
+
+// ParseShare decodes a share written by Share.Words with the same dictionary.
+// The caller should wipe the returned share.
+func ParseShare(words []string, dict dictionary.Dictionary) (Share, error) {
+	data, err := dictionary.DecodeWords(dict, words)
+	if err != nil {
+		return Share{}, err
+	}
+
+	defer safety.WipeBytes(data)
+
+	if len(data) < headerBytes+1+checksumBytes {
+		return Share{}, errors.New("too few words for a share")
+	}
+
+	body, checksum := data[:len(data)-checksumBytes], data[len(data)-checksumBytes:]
+
+	if sum := sha256.Sum256(body); string(sum[:checksumBytes]) != string(checksum) {
+		return Share{}, errors.New("checksum mismatch: a word is mistyped, missing or out of order")
+	}
+
+	if body[0] != formatVersion {
+		return Share{}, fmt.Errorf("unsupported share format version %d", body[0])
+	}
+
+	share := Share{
+		ID:        uint16(body[1])<<8 | uint16(body[2]),
+		Threshold: int(body[3]),
+		Index:     int(body[4]),
+		Value:     safety.NewSecureBuffer(len(body) - headerBytes),
+	}
+	share.Value.Write(body[headerBytes:])
+
+	if err := share.check(); err != nil {
+		share.Wipe()
+
+		return Share{}, err
+	}
+
+	return share, nil
+}
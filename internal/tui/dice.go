@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
+	"github.com/idelchi/pwgen/internal/generate"
+)
+
+// diceEntry is the state of the dice screen, where the words are picked by rolling physical dice.
+type diceEntry struct {
+	active  bool
+	digits  int
+	rolls   []string
+	current string
+	err     string
+}
+
+// openDice shows the dice screen. Dictionaries that are not diceware lists only show why.
+func (m *Model) openDice() {
+	m.dice = diceEntry{active: true}
+
+	digits, err := dictionary.DiceDigits(m.dictionary)
+	if err != nil {
+		m.dice.err = err.Error()
+
+		return
+	}
+
+	m.dice.digits = digits
+}
+
+// handleDiceKeys handles key presses on the dice screen.
+//
+//nolint:ireturn // tea.Model interface is required by Bubble Tea framework
+func (m Model) handleDiceKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch pressed := msg.String(); pressed {
+	case "ctrl+c":
+		(&m).cleanup()
+
+		return m, tea.Quit
+	case "esc":
+		m.dice = diceEntry{}
+	case "backspace":
+		switch {
+		case m.dice.current != "":
+			m.dice.current = m.dice.current[:len(m.dice.current)-1]
+		case len(m.dice.rolls) > 0:
+			last := m.dice.rolls[len(m.dice.rolls)-1]
+			m.dice.rolls = m.dice.rolls[:len(m.dice.rolls)-1]
+			m.dice.current = last[:len(last)-1]
+		}
+
+		m.dice.err = ""
+	case "enter":
+		if err := (&m).useRolls(); err != nil {
+			m.dice.err = err.Error()
+
+			return m, nil
+		}
+
+		m.dice = diceEntry{}
+	case "1", "2", "3", "4", "5", "6":
+		if m.dice.digits == 0 || len(m.dice.rolls) == maxWords {
+			return m, nil
+		}
+
+		m.dice.current += pressed
+		m.dice.err = ""
+
+		if len(m.dice.current) == m.dice.digits {
+			m.dice.rolls = append(m.dice.rolls, m.dice.current)
+			m.dice.current = ""
+		}
+	}
+
+	return m, nil
+}
+
+// useRolls replaces the passphrase with the words of the entered rolls,
+// keeping the separator and casing. The columns keep their rolled words when regenerated.
+func (m *Model) useRolls() error {
+	switch {
+	case m.dice.digits == 0:
+		return nil
+	case m.dice.current != "":
+		return fmt.Errorf("finish the roll %s (%d dice per word)", m.dice.current, m.dice.digits)
+	case len(m.dice.rolls) == 0:
+		return fmt.Errorf("roll %d dice for each word first", m.dice.digits)
+	}
+
+	currentSep := m.separators[m.currentSep]
+	builder := generate.NewPatternBuilder(m.dictionary, currentSep)
+	builder.SetSource(m.source)
+
+	pattern, err := builder.BuildFromRolls(m.dice.rolls, m.casing.String(), currentSep)
+	if err != nil {
+		return err
+	}
+
+	// The words are fixed, so a passphrase that is too long stays too long
+	passphrase, err := pattern.Generate()
+	if err != nil {
+		return err
+	}
+
+	if m.maxLength > 0 && utf8.RuneCountInString(passphrase) > m.maxLength {
+		return fmt.Errorf("these words are longer than %d characters; roll again or raise the maximum", m.maxLength)
+	}
+
+	m.words = len(m.dice.rolls)
+	m.digits, m.symbols = 0, 0
+	m.placement = generate.PlacementEnd
+	m.syllables = false
+	m.rolled = true
+
+	m.setPattern(pattern)
+
+	return m.generatePassphrase()
+}
+
+// diceView renders the dice screen.
+func (m Model) diceView() string {
+	var parts []string
+
+	parts = append(parts, m.styles.Title.Render("pwgen - Dice Rolls"))
+	parts = append(parts, "")
+
+	if m.dice.digits == 0 {
+		parts = append(parts, m.styles.Help.Render(m.dice.err+"\n\nPress esc to return."))
+
+		return strings.Join(parts, "\n")
+	}
+
+	var rolls strings.Builder
+
+	// The rolls were validated as they were typed
+	words, _ := dictionary.DiceWords(m.dictionary, m.dice.rolls)
+
+	for i, roll := range m.dice.rolls {
+		fmt.Fprintf(&rolls, "%2d  %s  %s\n", i+1, roll, words[i])
+	}
+
+	if len(m.dice.rolls) < maxWords {
+		fmt.Fprintf(&rolls, "%2d  %s%s\n", len(m.dice.rolls)+1,
+			m.dice.current, strings.Repeat("_", m.dice.digits-len(m.dice.current)))
+	}
+
+	parts = append(parts, m.styles.Passphrase.Render(rolls.String()))
+
+	if m.dice.err != "" {
+		parts = append(parts, m.styles.StrengthStyles["Weak"].Render(m.dice.err), "")
+	}
+
+	instructions := fmt.Sprintf(`Roll %d dice per word and type the faces (1-6) in order, up to %d words.
+The words are looked up in the numbered %s list.
+
+  backspace   Undo the last die
+  enter       Use these words with the current separator and casing
+  esc         Cancel`, m.dice.digits, maxWords, m.dictionary.Name())
+
+	parts = append(parts, m.styles.Help.Render(instructions))
+
+	return strings.Join(parts, "\n")
+}
@@ -28,6 +28,9 @@ type KeyMap struct {
 	SymbolsUp   key.Binding
 	SymbolsDown key.Binding
 	CycleCasing key.Binding
+	Placement   key.Binding
+	MaxLength   key.Binding
+	Dice        key.Binding
 	IncreaseKey key.Binding
 	DecreaseKey key.Binding
 	Column1     key.Binding
@@ -67,7 +70,7 @@ func DefaultKeyMap() KeyMap {
 		ToggleView:  newKeyBindingWithHelp("v", "show/hide passphrase", "v"),
 		NewAll:      newKeyBindingWithHelp("n", "new passphrase", "n"),
 		Separators:  newKeyBindingWithHelp("s", "toggle separators", "s"),
-		Patterns:    newKeyBindingWithHelp("p", "cycle patterns", "p"),
+		Patterns:    newKeyBindingWithHelp("p", "toggle words/pronounceable", "p"),
 		WordsUp:     newKeyBindingWithHelp("w", "increase words", "w"),
 		WordsDown:   newKeyBindingWithHelp("W", "decrease words", "W"),
 		DigitsUp:    newKeyBindingWithHelp("d", "increase digits", "d"),
@@ -77,6 +80,9 @@ func DefaultKeyMap() KeyMap {
 		IncreaseKey: newKeyBindingWithHelp("+", "increase (context sensitive)", "+", "="),
 		DecreaseKey: newKeyBindingWithHelp("-", "decrease (context sensitive)", "-", "_"),
 		CycleCasing: newKeyBindingWithHelp("ctrl+s", "cycle casing", "ctrl+s"),
+		Placement:   newKeyBindingWithHelp("i", "cycle digit/symbol placement", "i"),
+		MaxLength:   newKeyBindingWithHelp("m", "cycle maximum length", "m"),
+		Dice:        newKeyBindingWithHelp("r", "enter dice rolls", "r"),
 		Column1:     newColumnKeyBinding(1),
 		Column2:     newColumnKeyBinding(2), //nolint:mnd // column numbers are contextually clear
 		Column3:     newColumnKeyBinding(3), //nolint:mnd // column numbers are contextually clear
@@ -101,7 +107,7 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 		{k.Copy, k.ToggleView, k.NewAll},
 		{k.Separators, k.WordsUp, k.WordsDown, k.DigitsUp, k.DigitsDown},
 		{k.SymbolsUp, k.SymbolsDown, k.IncreaseKey, k.DecreaseKey},
-		{k.CycleCasing, k.Patterns},
+		{k.CycleCasing, k.Patterns, k.Placement, k.MaxLength, k.Dice},
 		{k.Column1, k.Column2, k.Column3, k.Column4, k.Column5},
 		{k.Column6, k.Column7, k.Column8, k.Column9},
 		{k.Help, k.Quit},
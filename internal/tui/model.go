@@ -1,7 +1,11 @@
 package tui
 
 import (
+	"crypto/rand"
+	"fmt"
+	"io"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/help"
 	tea "github.com/charmbracelet/bubbletea"
@@ -22,15 +26,22 @@ const (
 
 	// Default configuration.
 	defaultWords = 4
+
+	// syllablesPerColumn is the length of a pronounceable column, shorter than most words.
+	syllablesPerColumn = 3
+
+	// maxLengthAttempts is the number of passphrases tried to fit the maximum length.
+	maxLengthAttempts = 10000
 )
 
 // Model represents the TUI application state.
 //
 //nolint:recvcheck // Mixed receivers required by Bubble Tea framework patterns
 type Model struct {
-	// Generator and dictionary
+	// Generator, dictionary and source of randomness
 	generator  *generate.Generator
 	dictionary dictionary.Dictionary
+	source     io.Reader
 
 	// Current passphrase
 	passphrase *safety.SecureString
@@ -46,12 +57,19 @@ type Model struct {
 
 	// Configuration state
 	words      int
+	syllables  bool
 	digits     int
 	symbols    int
 	casing     generate.CaseStyle
+	placement  generate.Placement
+	maxLength  int
 	separators []string
 	currentSep int
 
+	// Dice screen, and whether the words come from dice rolls
+	dice   diceEntry
+	rolled bool
+
 	// Key bindings and help
 	keys   KeyMap
 	help   help.Model
@@ -70,21 +88,17 @@ type Column struct {
 	Focused bool
 }
 
-// NewModel creates a new TUI model.
-func NewModel() (*Model, error) {
-	// Load default dictionary
-	dict, err := dictionary.GetDictionary("eff")
-	if err != nil {
-		return nil, err
-	}
-
+// NewModel creates a new TUI model using the given dictionary.
+// Passphrases longer than maxLength characters are generated again, unless maxLength is zero.
+func NewModel(dict dictionary.Dictionary, maxLength int) (*Model, error) {
 	// Create generator
 	generator := generate.NewGenerator(dict, "-")
 
 	// Initialize with default pattern
 	builder := generate.NewPatternBuilder(dict, "-")
+	builder.SetSource(rand.Reader)
 
-	pattern, err := builder.BuildFromOptions(defaultWords, minDigits, minSymbols, "mixed", "-", false, false, false)
+	pattern, err := builder.BuildFromOptions(defaultWords, minDigits, minSymbols, "mixed", "-", "end", false, false, false)
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +116,7 @@ func NewModel() (*Model, error) {
 	model := &Model{
 		generator:  generator,
 		dictionary: dict,
+		source:     rand.Reader,
 		pattern:    pattern,
 		masked:     true,
 		focused:    0,
@@ -113,6 +128,7 @@ func NewModel() (*Model, error) {
 		digits:     minDigits,
 		symbols:    minSymbols,
 		casing:     generate.CaseMixed,
+		maxLength:  maxLength,
 		separators: []string{"-", "_", ".", " ", ""},
 		currentSep: 0,
 
@@ -135,24 +151,55 @@ func (m Model) Init() tea.Cmd {
 }
 
 // generatePassphrase creates a new passphrase by regenerating unlocked columns.
+// Under a maximum length, the unlocked columns are regenerated until the passphrase fits.
 func (m *Model) generatePassphrase() error {
-	parts := make([]string, 0, len(m.columns))
+	// Calculate entropy of the passphrases that fit, which also fails if too few of them do
+	policy := generate.Policy{MaxLength: m.maxLength}
+
+	entropy, err := policy.Entropy(m.pattern)
+	if err != nil {
+		return err
+	}
 
-	for i, column := range m.columns { //nolint:varnamelen // i is standard loop var
-		if !column.Locked {
-			value, err := column.Token.Generate()
+	values := make([]string, len(m.columns))
+
+	var passphraseStr string
+
+	for attempt := 0; ; attempt++ {
+		generation := generate.NewGeneration(m.source)
+
+		for i, column := range m.columns { //nolint:varnamelen // i is standard loop var
+			values[i] = column.Value
+
+			if column.Locked {
+				generation.Keep(column.Token)
+
+				continue
+			}
+
+			value, err := column.Token.Generate(generation)
 			if err != nil {
 				return err
 			}
 
-			m.columns[i].Value = value
+			values[i] = value
 		}
 
-		parts = append(parts, m.columns[i].Value)
+		// Join all parts to create the passphrase - tokens already include separators
+		passphraseStr = strings.Join(values, "")
+
+		if m.maxLength == 0 || utf8.RuneCountInString(passphraseStr) <= m.maxLength {
+			break
+		}
+
+		if attempt == maxLengthAttempts {
+			return fmt.Errorf("no passphrase within %d characters; unlock columns or use fewer words", m.maxLength)
+		}
 	}
 
-	// Join all parts to create the passphrase - tokens already include separators
-	passphraseStr := strings.Join(parts, "")
+	for i := range m.columns {
+		m.columns[i].Value = values[i]
+	}
 
 	// Store in secure string
 	if m.passphrase != nil {
@@ -161,8 +208,7 @@ func (m *Model) generatePassphrase() error {
 
 	m.passphrase = safety.NewSecureString(passphraseStr)
 
-	// Calculate entropy and strength
-	m.entropy = m.pattern.EntropyBits()
+	m.entropy = entropy
 	m.strength = m.calculateStrength(m.entropy)
 
 	return nil
@@ -232,29 +278,37 @@ func (m *Model) cycleSeparator() error {
 func (m *Model) regeneratePattern() error {
 	currentSep := m.separators[m.currentSep]
 	builder := generate.NewPatternBuilder(m.dictionary, currentSep)
-
-	var casingStr string
-
-	switch m.casing {
-	case generate.CaseLower:
-		casingStr = "lower"
-	case generate.CaseUpper:
-		casingStr = "upper"
-	case generate.CaseTitle:
-		casingStr = "title"
-	case generate.CaseMixed:
-		casingStr = "mixed"
-	}
+	builder.SetSource(m.source)
 
 	pattern, err := builder.BuildFromOptions(
 		m.words, m.digits, m.symbols,
-		casingStr, currentSep,
+		m.casing.String(), currentSep, m.placement.String(),
 		false, false, false, // kebab, snake, camel
 	)
 	if err != nil {
 		return err
 	}
 
+	if m.syllables {
+		usePronounceable(pattern.Tokens)
+	}
+
+	// Check that the passphrases can fit the maximum length before replacing the columns
+	policy := generate.Policy{MaxLength: m.maxLength}
+	if _, err := policy.Entropy(pattern); err != nil {
+		return err
+	}
+
+	m.rolled = false
+
+	m.setPattern(pattern)
+
+	// Generate new passphrase
+	return m.generatePassphrase()
+}
+
+// setPattern replaces the pattern and creates a column for each of its tokens, keeping the focus if possible.
+func (m *Model) setPattern(pattern *generate.Pattern) {
 	m.pattern = pattern
 
 	// Recreate columns from new pattern
@@ -274,9 +328,75 @@ func (m *Model) regeneratePattern() error {
 			m.columns[0].Focused = true
 		}
 	}
+}
 
-	// Generate new passphrase
-	return m.generatePassphrase()
+// usePronounceable replaces the word tokens with pronounceable syllable tokens,
+// including those shuffled or taking insertions under random or inside placement.
+func usePronounceable(tokens []generate.Token) {
+	for i, token := range tokens {
+		switch t := token.(type) {
+		case *generate.WordToken:
+			tokens[i] = &generate.SyllableToken{Count: syllablesPerColumn, Casing: t.Casing}
+		case *generate.ShuffleToken:
+			usePronounceable(t.Tokens)
+		case *generate.InsideToken:
+			usePronounceable(t.Tokens)
+		}
+	}
+}
+
+// togglePronounceable switches the word columns between dictionary words and
+// pronounceable syllables and regenerates the pattern.
+func (m *Model) togglePronounceable() error {
+	m.syllables = !m.syllables
+
+	return m.regeneratePattern()
+}
+
+// cyclePlacement cycles where digits and symbols go (end, random, inside) and regenerates the pattern.
+// Under random and inside placement the whole passphrase is a single column. Inside placement is
+// skipped if the words are too short to take all digits and symbols.
+func (m *Model) cyclePlacement() error {
+	switch m.placement {
+	case generate.PlacementEnd:
+		m.placement = generate.PlacementRandom
+	case generate.PlacementRandom:
+		m.placement = generate.PlacementInside
+	case generate.PlacementInside:
+		m.placement = generate.PlacementEnd
+	}
+
+	if err := m.regeneratePattern(); err != nil {
+		m.placement = generate.PlacementEnd
+
+		return m.regeneratePattern()
+	}
+
+	return nil
+}
+
+// cycleMaxLength cycles the maximum length through none, 32, 24, 20 and 16 characters
+// and regenerates the passphrase, skipping limits that the current configuration cannot meet.
+func (m *Model) cycleMaxLength() error {
+	limits := []int{32, 24, 20, 16} //nolint:mnd // common site limits
+
+	for {
+		next := 0
+
+		for _, limit := range limits {
+			if m.maxLength == 0 || limit < m.maxLength {
+				next = limit
+
+				break
+			}
+		}
+
+		m.maxLength = next
+
+		if err := m.generatePassphrase(); err == nil || next == 0 {
+			return err
+		}
+	}
 }
 
 // adjustWords changes the word count and regenerates the pattern.
@@ -290,9 +410,17 @@ func (m *Model) adjustWords(delta int) error {
 		newWords = maxWords
 	}
 
+	previous := m.words
 	m.words = newWords
 
-	return m.regeneratePattern()
+	// Keep the previous count if the passphrase no longer fits the maximum length
+	if err := m.regeneratePattern(); err != nil {
+		m.words = previous
+
+		return err
+	}
+
+	return nil
 }
 
 // adjustDigits changes the digit count and regenerates the pattern.
@@ -306,9 +434,17 @@ func (m *Model) adjustDigits(delta int) error {
 		newDigits = maxDigits
 	}
 
+	previous := m.digits
 	m.digits = newDigits
 
-	return m.regeneratePattern()
+	// Keep the previous count if the passphrase no longer fits the maximum length
+	if err := m.regeneratePattern(); err != nil {
+		m.digits = previous
+
+		return err
+	}
+
+	return nil
 }
 
 // adjustSymbols changes the symbol count and regenerates the pattern.
@@ -322,9 +458,17 @@ func (m *Model) adjustSymbols(delta int) error {
 		newSymbols = maxSymbols
 	}
 
+	previous := m.symbols
 	m.symbols = newSymbols
 
-	return m.regeneratePattern()
+	// Keep the previous count if the passphrase no longer fits the maximum length
+	if err := m.regeneratePattern(); err != nil {
+		m.symbols = previous
+
+		return err
+	}
+
+	return nil
 }
 
 // cycleCasing cycles to the next casing style and regenerates the pattern.
@@ -337,6 +481,14 @@ func (m *Model) cycleCasing() error {
 	case generate.CaseTitle:
 		m.casing = generate.CaseMixed
 	case generate.CaseMixed:
+		m.casing = generate.CaseAlternating
+	case generate.CaseAlternating:
+		m.casing = generate.CaseRandomTitle
+	case generate.CaseRandomTitle:
+		m.casing = generate.CaseFirstUpper
+	case generate.CaseFirstUpper:
+		m.casing = generate.CaseInvertedTitle
+	case generate.CaseInvertedTitle:
 		m.casing = generate.CaseLower
 	}
 
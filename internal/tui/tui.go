@@ -4,12 +4,15 @@ import (
 	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/idelchi/pwgen/internal/dictionary"
 )
 
-// Run starts the TUI application.
-func Run() error {
+// Run starts the TUI application using the given dictionary.
+// Passphrases are kept within maxLength characters, unless maxLength is zero.
+func Run(dict dictionary.Dictionary, maxLength int) error {
 	// Create model
-	model, err := NewModel()
+	model, err := NewModel(dict, maxLength)
 	if err != nil {
 		return fmt.Errorf("creating TUI model: %w", err)
 	}
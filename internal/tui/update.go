@@ -36,6 +36,10 @@ func (m Model) handleWindowSize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 //
 //nolint:ireturn // tea.Model interface is required by Bubble Tea framework
 func (m Model) handleKeyMessage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.dice.active {
+		return m.handleDiceKeys(msg)
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.Quit):
 		(&m).cleanup()
@@ -98,12 +102,32 @@ func (m Model) handleConfigKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 		return m, nil
 	case key.Matches(msg, m.keys.Patterns):
+		if err := (&m).togglePronounceable(); err != nil {
+			return m, nil
+		}
+
 		return m, nil
 	case key.Matches(msg, m.keys.CycleCasing):
 		if err := (&m).cycleCasing(); err != nil {
 			return m, nil
 		}
 
+		return m, nil
+	case key.Matches(msg, m.keys.Placement):
+		if err := (&m).cyclePlacement(); err != nil {
+			return m, nil
+		}
+
+		return m, nil
+	case key.Matches(msg, m.keys.MaxLength):
+		if err := (&m).cycleMaxLength(); err != nil {
+			return m, nil
+		}
+
+		return m, nil
+	case key.Matches(msg, m.keys.Dice):
+		(&m).openDice()
+
 		return m, nil
 	default:
 		return m.handleAdjustmentKeys(msg)
@@ -182,6 +206,15 @@ func (m Model) handleCopy() (tea.Model, tea.Cmd) {
 //
 //nolint:ireturn // tea.Model interface is required by Bubble Tea framework
 func (m Model) handleNewAll() (tea.Model, tea.Cmd) {
+	// Rolled words stay until the pattern is rebuilt with random ones
+	if m.rolled {
+		if err := (&m).regeneratePattern(); err != nil {
+			return m, nil
+		}
+
+		return m, nil
+	}
+
 	(&m).unlockAll()
 
 	if err := (&m).generatePassphrase(); err != nil {
@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -70,6 +71,10 @@ func (m Model) View() string {
 		return m.helpView()
 	}
 
+	if m.dice.active {
+		return m.diceView()
+	}
+
 	var parts []string
 
 	// Title
@@ -134,7 +139,7 @@ func (m Model) renderColumns() string {
 // renderStatus renders the status line with entropy and strength information.
 func (m Model) renderStatus() string {
 	entropyStr := fmt.Sprintf("%.1f bits", m.entropy)
-	lengthStr := fmt.Sprintf("%d chars", len(m.getPassphrase()))
+	lengthStr := fmt.Sprintf("%d chars", utf8.RuneCountInString(m.getPassphrase()))
 
 	strengthStyle, ok := m.styles.StrengthStyles[m.strength]
 	if !ok {
@@ -154,8 +159,21 @@ func (m Model) renderStatus() string {
 		currentSep = "space"
 	}
 
-	configStr := fmt.Sprintf("Config: %dw %dd %ds %s sep=%s",
-		m.words, m.digits, m.symbols, casingStr, currentSep)
+	wordUnit := "w"
+	if m.syllables {
+		wordUnit = "p"
+	}
+
+	configStr := fmt.Sprintf("Config: %d%s %dd %ds %s sep=%s place=%s",
+		m.words, wordUnit, m.digits, m.symbols, casingStr, currentSep, m.placement)
+
+	if m.maxLength > 0 {
+		configStr += fmt.Sprintf(" max=%d", m.maxLength)
+	}
+
+	if m.rolled {
+		configStr += " dice"
+	}
 
 	status := fmt.Sprintf("Entropy: %s  |  Length: %s  |  Strength: %s  |  %s",
 		entropyStr, lengthStr, strengthStr, configStr)
@@ -195,7 +213,16 @@ CUSTOMIZATION CONTROLS:
   d / D       Increase / decrease digit count (0-5)
   x / X       Increase / decrease symbol count (0-5)
   + / -       Universal increase / smart decrease
-  ctrl+s      Cycle casing: mixed → lower → upper → title → mixed
+  ctrl+s      Cycle casing: mixed → alternating → random-title → first-upper →
+              inverted-title → lower → upper → title → mixed
+  p           Toggle word columns between dictionary words and
+              pronounceable syllables (shorter, for length-capped systems)
+  i           Cycle digit/symbol placement: end → random → inside → end
+              (random and inside show the passphrase as a single column)
+  m           Cycle maximum length: none → 32 → 24 → 20 → 16 → none
+              (longer passphrases are regenerated; entropy counts only those that fit)
+  r           Enter physical dice rolls to pick the words from the numbered EFF list
+              (the words stay until n or a configuration change brings back random ones)
   1-9         Lock/unlock specific columns
 
 DISPLAY CONTROLS: